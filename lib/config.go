@@ -0,0 +1,305 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lib
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+const (
+	ConfigTypeString  = "string"
+	ConfigTypeInteger = "integer"
+	ConfigTypeFloat   = "float"
+	ConfigTypeBoolean = "boolean"
+)
+
+const (
+	ConfigErrorMissing      = "missing"
+	ConfigErrorUnknown      = "unknown"
+	ConfigErrorTypeMismatch = "type_mismatch"
+	ConfigErrorInvalidValue = "invalid_value"
+)
+
+var configTypeAliases = map[string]string{
+	"string":  ConfigTypeString,
+	"integer": ConfigTypeInteger,
+	"int":     ConfigTypeInteger,
+	"float":   ConfigTypeFloat,
+	"number":  ConfigTypeFloat,
+	"boolean": ConfigTypeBoolean,
+	"bool":    ConfigTypeBoolean,
+}
+
+// ValidateConfigDefinitions checks that the config values of an operator are consistent in themselves.
+func ValidateConfigDefinitions(values []ConfigValue) error {
+	names := make(map[string]struct{}, len(values))
+	for _, value := range values {
+		if _, ok := names[value.Name]; ok {
+			return NewInvalidInputError(fmt.Errorf("config value %q is defined more than once", value.Name))
+		}
+		names[value.Name] = struct{}{}
+		if err := value.Validate(); err != nil {
+			return NewInvalidInputError(fmt.Errorf("config value %q: %w", value.Name, err))
+		}
+	}
+	return nil
+}
+
+// Validate checks that the constraints, options and default of a config value fit its type and each other.
+func (v ConfigValue) Validate() error {
+	if v.Name == "" {
+		return errors.New("missing name")
+	}
+	configType, ok := configTypeAliases[v.Type]
+	if !ok {
+		return fmt.Errorf("unknown type %q", v.Type)
+	}
+	if v.Pattern != "" {
+		if configType != ConfigTypeString {
+			return errors.New("pattern is only allowed for type string")
+		}
+		if _, err := regexp.Compile(v.Pattern); err != nil {
+			return fmt.Errorf("invalid pattern: %w", err)
+		}
+	}
+	if v.Min != nil || v.Max != nil {
+		if configType != ConfigTypeInteger && configType != ConfigTypeFloat {
+			return errors.New("min and max are only allowed for numeric types")
+		}
+		if v.Min != nil && v.Max != nil && *v.Min > *v.Max {
+			return errors.New("min must not be greater than max")
+		}
+	}
+	for _, option := range v.Options {
+		if err := v.CheckValue(option); err != nil {
+			return fmt.Errorf("invalid option %v: %w", option, err)
+		}
+	}
+	if v.Default != nil {
+		if v.Required {
+			return errors.New("required values must not define a default")
+		}
+		if err := v.CheckValue(v.Default); err != nil {
+			return fmt.Errorf("invalid default: %w", err)
+		}
+		if len(v.Options) > 0 && !v.hasOption(v.Default) {
+			return errors.New("default is not one of the options")
+		}
+	}
+	return nil
+}
+
+// CheckValue checks a single value against the type and range constraints of the config value.
+// Options are not considered.
+func (v ConfigValue) CheckValue(value any) error {
+	if err := v.checkType(value); err != nil {
+		return err
+	}
+	switch configTypeAliases[v.Type] {
+	case ConfigTypeString:
+		if v.Pattern != "" {
+			re, err := regexp.Compile(v.Pattern)
+			if err != nil {
+				return err
+			}
+			if !re.MatchString(value.(string)) {
+				return fmt.Errorf("does not match pattern %s", v.Pattern)
+			}
+		}
+	case ConfigTypeInteger, ConfigTypeFloat:
+		f, _ := toFloat(value)
+		if v.Min != nil && f < *v.Min {
+			return fmt.Errorf("must not be less than %v", *v.Min)
+		}
+		if v.Max != nil && f > *v.Max {
+			return fmt.Errorf("must not be greater than %v", *v.Max)
+		}
+	}
+	return nil
+}
+
+func (v ConfigValue) checkType(value any) error {
+	switch configTypeAliases[v.Type] {
+	case ConfigTypeString:
+		if _, ok := value.(string); ok {
+			return nil
+		}
+	case ConfigTypeInteger:
+		if f, ok := toFloat(value); ok && f == math.Trunc(f) {
+			return nil
+		}
+	case ConfigTypeFloat:
+		if _, ok := toFloat(value); ok {
+			return nil
+		}
+	case ConfigTypeBoolean:
+		if _, ok := value.(bool); ok {
+			return nil
+		}
+	default:
+		return fmt.Errorf("unknown type %q", v.Type)
+	}
+	return fmt.Errorf("expected type %s", v.Type)
+}
+
+// ValidateConfig checks a user supplied configuration against the config values of an operator.
+// All problems are reported, ordered by field name.
+func ValidateConfig(definitions []ConfigValue, config map[string]any) (response ConfigValidationResponse) {
+	response.Errors = make([]ConfigFieldError, 0)
+	defined := make(map[string]struct{}, len(definitions))
+	for _, definition := range definitions {
+		defined[definition.Name] = struct{}{}
+		value, ok := config[definition.Name]
+		if !ok || value == nil {
+			if definition.Required {
+				response.Errors = append(response.Errors, ConfigFieldError{
+					Field:   definition.Name,
+					Code:    ConfigErrorMissing,
+					Message: "required value is missing",
+				})
+			}
+			continue
+		}
+		if err := definition.checkType(value); err != nil {
+			response.Errors = append(response.Errors, ConfigFieldError{
+				Field:   definition.Name,
+				Code:    ConfigErrorTypeMismatch,
+				Message: err.Error(),
+			})
+			continue
+		}
+		if err := definition.CheckValue(value); err != nil {
+			response.Errors = append(response.Errors, ConfigFieldError{
+				Field:   definition.Name,
+				Code:    ConfigErrorInvalidValue,
+				Message: err.Error(),
+			})
+			continue
+		}
+		if len(definition.Options) > 0 && !definition.hasOption(value) {
+			response.Errors = append(response.Errors, ConfigFieldError{
+				Field:   definition.Name,
+				Code:    ConfigErrorInvalidValue,
+				Message: "value is not one of the options",
+			})
+		}
+	}
+	for name := range config {
+		if _, ok := defined[name]; !ok {
+			response.Errors = append(response.Errors, ConfigFieldError{
+				Field:   name,
+				Code:    ConfigErrorUnknown,
+				Message: "unknown config value",
+			})
+		}
+	}
+	slices.SortFunc(response.Errors, func(a, b ConfigFieldError) int {
+		return strings.Compare(a.Field, b.Field)
+	})
+	response.Valid = len(response.Errors) == 0
+	return
+}
+
+func (v ConfigValue) hasOption(value any) bool {
+	return slices.ContainsFunc(v.Options, func(option any) bool {
+		return jsonEqual(option, value)
+	})
+}
+
+// RedactSecrets removes the defaults of secret config values.
+func (o *Operator) RedactSecrets() {
+	if o.Config == nil {
+		return
+	}
+	config := make([]ConfigValue, len(o.Config))
+	for i, value := range o.Config {
+		if value.Secret {
+			value.Default = nil
+		}
+		config[i] = value
+	}
+	o.Config = config
+}
+
+// KeepSecrets restores the stored definitions of secret config values that were sent back redacted.
+// Values are matched by name, a default given explicitly is kept.
+// A stored secret default must never be checked against constraints the caller supplied, as the validation error would
+// reveal it. Changes to the type or constraints of a redacted secret value are therefore rejected, only the description may change.
+func (o *Operator) KeepSecrets(stored Operator) error {
+	secrets := map[string]ConfigValue{}
+	for _, value := range stored.Config {
+		if value.Secret && value.Default != nil {
+			secrets[value.Name] = value
+		}
+	}
+	if len(secrets) == 0 || o.Config == nil {
+		return nil
+	}
+	config := make([]ConfigValue, len(o.Config))
+	for i, value := range o.Config {
+		if secret, ok := secrets[value.Name]; ok && value.Secret && value.Default == nil {
+			secret.Description = value.Description
+			redacted := secret
+			redacted.Default = nil
+			if !jsonEqual(redacted, value) {
+				return NewForbiddenError(fmt.Errorf("config value %q is secret, only the owner may change its type or constraints", value.Name))
+			}
+			value = secret
+		}
+		config[i] = value
+	}
+	o.Config = config
+	return nil
+}
+
+func toFloat(value any) (float64, bool) {
+	switch n := value.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// jsonEqual compares values by their JSON representation, numbers decoded from JSON and BSON differ in type only.
+func jsonEqual(a any, b any) bool {
+	aj, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	bj, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+	return string(aj) == string(bj)
+}
@@ -0,0 +1,253 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lib
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestConfigValueValidate(t *testing.T) {
+	cases := []struct {
+		name  string
+		value ConfigValue
+		valid bool
+	}{
+		{"string", ConfigValue{Name: "a", Type: "string"}, true},
+		{"alias", ConfigValue{Name: "a", Type: "int", Default: 3.0}, true},
+		{"missing name", ConfigValue{Type: "string"}, false},
+		{"unknown type", ConfigValue{Name: "a", Type: "date"}, false},
+		{"pattern", ConfigValue{Name: "a", Type: "string", Pattern: "^[a-z]+$", Default: "abc"}, true},
+		{"invalid pattern", ConfigValue{Name: "a", Type: "string", Pattern: "("}, false},
+		{"pattern on number", ConfigValue{Name: "a", Type: "float", Pattern: ".*"}, false},
+		{"default not matching pattern", ConfigValue{Name: "a", Type: "string", Pattern: "^[a-z]+$", Default: "ABC"}, false},
+		{"range", ConfigValue{Name: "a", Type: "integer", Min: ptr(1.0), Max: ptr(10.0), Default: 5.0}, true},
+		{"range on string", ConfigValue{Name: "a", Type: "string", Min: ptr(1.0)}, false},
+		{"min greater than max", ConfigValue{Name: "a", Type: "float", Min: ptr(2.0), Max: ptr(1.0)}, false},
+		{"default below min", ConfigValue{Name: "a", Type: "float", Min: ptr(1.0), Default: 0.5}, false},
+		{"default above max", ConfigValue{Name: "a", Type: "float", Max: ptr(1.0), Default: 1.5}, false},
+		{"fractional integer default", ConfigValue{Name: "a", Type: "integer", Default: 1.5}, false},
+		{"default type mismatch", ConfigValue{Name: "a", Type: "boolean", Default: "true"}, false},
+		{"required with default", ConfigValue{Name: "a", Type: "string", Required: true, Default: "x"}, false},
+		{"options", ConfigValue{Name: "a", Type: "string", Options: []any{"x", "y"}, Default: "y"}, true},
+		{"invalid option", ConfigValue{Name: "a", Type: "integer", Options: []any{1.0, "two"}}, false},
+		{"option out of range", ConfigValue{Name: "a", Type: "integer", Max: ptr(5.0), Options: []any{1.0, 6.0}}, false},
+		{"default not an option", ConfigValue{Name: "a", Type: "string", Options: []any{"x", "y"}, Default: "z"}, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := c.value.Validate()
+			if c.valid && err != nil {
+				t.Errorf("expected valid, got %v", err)
+			}
+			if !c.valid && err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestValidateConfigDefinitions(t *testing.T) {
+	err := ValidateConfigDefinitions([]ConfigValue{{Name: "a", Type: "string"}, {Name: "a", Type: "float"}})
+	var invalid *InvalidInputError
+	if !errors.As(err, &invalid) {
+		t.Errorf("expected invalid input error for duplicate name, got %v", err)
+	}
+	if err = ValidateConfigDefinitions([]ConfigValue{{Name: "a", Type: "string"}, {Name: "b", Type: "float"}}); err != nil {
+		t.Errorf("expected valid definitions, got %v", err)
+	}
+}
+
+func TestValidateConfig(t *testing.T) {
+	definitions := []ConfigValue{
+		{Name: "name", Type: "string", Required: true, Pattern: "^[a-z]+$"},
+		{Name: "count", Type: "integer", Min: ptr(0.0), Max: ptr(10.0)},
+		{Name: "mode", Type: "string", Options: []any{"fast", "slow"}},
+		{Name: "enabled", Type: "boolean"},
+	}
+	cases := []struct {
+		name     string
+		config   string
+		expected []ConfigFieldError
+	}{
+		{"valid", `{"name":"abc","count":3,"mode":"fast","enabled":true}`, nil},
+		{"optional values missing", `{"name":"abc"}`, nil},
+		{"null optional value", `{"name":"abc","count":null}`, nil},
+		{"required value missing", `{}`, []ConfigFieldError{{Field: "name", Code: ConfigErrorMissing}}},
+		{"required value null", `{"name":null}`, []ConfigFieldError{{Field: "name", Code: ConfigErrorMissing}}},
+		{"type mismatch", `{"name":1,"enabled":"yes"}`, []ConfigFieldError{
+			{Field: "enabled", Code: ConfigErrorTypeMismatch},
+			{Field: "name", Code: ConfigErrorTypeMismatch},
+		}},
+		{"fractional integer", `{"name":"abc","count":1.5}`, []ConfigFieldError{{Field: "count", Code: ConfigErrorTypeMismatch}}},
+		{"out of range", `{"name":"abc","count":11}`, []ConfigFieldError{{Field: "count", Code: ConfigErrorInvalidValue}}},
+		{"pattern mismatch", `{"name":"ABC"}`, []ConfigFieldError{{Field: "name", Code: ConfigErrorInvalidValue}}},
+		{"not an option", `{"name":"abc","mode":"medium"}`, []ConfigFieldError{{Field: "mode", Code: ConfigErrorInvalidValue}}},
+		{"unknown values sorted", `{"name":"abc","zzz":1,"aaa":2}`, []ConfigFieldError{
+			{Field: "aaa", Code: ConfigErrorUnknown},
+			{Field: "zzz", Code: ConfigErrorUnknown},
+		}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var config map[string]any
+			if err := json.Unmarshal([]byte(c.config), &config); err != nil {
+				t.Fatal(err)
+			}
+			response := ValidateConfig(definitions, config)
+			if response.Valid != (len(c.expected) == 0) {
+				t.Errorf("expected valid %v, got %v", len(c.expected) == 0, response.Valid)
+			}
+			if len(response.Errors) != len(c.expected) {
+				t.Fatalf("expected %v, got %v", c.expected, response.Errors)
+			}
+			for i, expected := range c.expected {
+				if response.Errors[i].Field != expected.Field || response.Errors[i].Code != expected.Code {
+					t.Errorf("expected %v, got %v", c.expected, response.Errors)
+				}
+				if response.Errors[i].Message == "" {
+					t.Errorf("expected message for %s", response.Errors[i].Field)
+				}
+			}
+		})
+	}
+}
+
+func secretOperator() Operator {
+	return Operator{Config: []ConfigValue{
+		{Name: "url", Type: "string", Default: "http://localhost"},
+		{Name: "token", Type: "string", Description: "api token", Pattern: "^[a-z0-9]+$", Secret: true, Default: "s3cr3t"},
+		{Name: "limit", Type: "integer", Secret: true, Min: ptr(0.0), Max: ptr(100.0), Default: 42.0},
+	}}
+}
+
+func TestRedactSecrets(t *testing.T) {
+	stored := secretOperator()
+	redacted := stored
+	redacted.RedactSecrets()
+	if redacted.Config[0].Default != "http://localhost" {
+		t.Errorf("expected non secret default to be kept, got %v", redacted.Config[0].Default)
+	}
+	for _, value := range redacted.Config[1:] {
+		if value.Default != nil {
+			t.Errorf("expected default of %s to be redacted, got %v", value.Name, value.Default)
+		}
+	}
+	if stored.Config[1].Default != "s3cr3t" {
+		t.Error("expected stored operator to be unchanged")
+	}
+}
+
+func TestKeepSecrets(t *testing.T) {
+	cases := []struct {
+		name      string
+		change    func(o *Operator)
+		expected  func(o *Operator)
+		forbidden bool
+	}{
+		{
+			name:     "redacted round trip",
+			change:   func(o *Operator) {},
+			expected: func(o *Operator) {},
+		},
+		{
+			name:     "description changed",
+			change:   func(o *Operator) { o.Config[1].Description = "new token" },
+			expected: func(o *Operator) { o.Config[1].Description = "new token" },
+		},
+		{
+			name:     "explicit default",
+			change:   func(o *Operator) { o.Config[1].Default = "other" },
+			expected: func(o *Operator) { o.Config[1].Default = "other" },
+		},
+		{
+			name:     "secret flag removed",
+			change:   func(o *Operator) { o.Config[1].Secret = false },
+			expected: func(o *Operator) { o.Config[1].Secret = false; o.Config[1].Default = nil },
+		},
+		{
+			name:     "value removed",
+			change:   func(o *Operator) { o.Config = o.Config[:2] },
+			expected: func(o *Operator) { o.Config = o.Config[:2] },
+		},
+		{
+			name:      "pattern changed",
+			change:    func(o *Operator) { o.Config[1].Pattern = "^s.*$" },
+			forbidden: true,
+		},
+		{
+			name:      "options added",
+			change:    func(o *Operator) { o.Config[1].Options = []any{"a", "b"} },
+			forbidden: true,
+		},
+		{
+			name:      "range changed",
+			change:    func(o *Operator) { o.Config[2].Max = ptr(41.0) },
+			forbidden: true,
+		},
+		{
+			name:      "made required",
+			change:    func(o *Operator) { o.Config[2].Required = true },
+			forbidden: true,
+		},
+		{
+			name:      "type changed",
+			change:    func(o *Operator) { o.Config[2].Type = "float" },
+			forbidden: true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			stored := secretOperator()
+			operator := secretOperator()
+			operator.RedactSecrets()
+			c.change(&operator)
+			err := operator.KeepSecrets(stored)
+			if c.forbidden {
+				var forbidden *ForbiddenError
+				if !errors.As(err, &forbidden) {
+					t.Fatalf("expected forbidden error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected := secretOperator()
+			c.expected(&expected)
+			if !reflect.DeepEqual(operator.Config, expected.Config) {
+				t.Errorf("expected %+v, got %+v", expected.Config, operator.Config)
+			}
+		})
+	}
+}
+
+func TestKeepSecretsWithoutStoredSecrets(t *testing.T) {
+	operator := Operator{Config: []ConfigValue{{Name: "token", Type: "string", Secret: true, Pattern: "^a$"}}}
+	if err := operator.KeepSecrets(Operator{Config: []ConfigValue{{Name: "token", Type: "string", Secret: true}}}); err != nil {
+		t.Fatal(err)
+	}
+	if operator.Config[0].Pattern != "^a$" || operator.Config[0].Default != nil {
+		t.Errorf("expected config to be unchanged, got %+v", operator.Config[0])
+	}
+}
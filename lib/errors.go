@@ -0,0 +1,203 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lib
+
+import (
+	"errors"
+	"net/http"
+)
+
+// ProblemDetails is the error response body as described by RFC 7807.
+type ProblemDetails struct {
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	Detail    string `json:"detail,omitempty"`
+	Instance  string `json:"instance,omitempty"`
+	RequestId string `json:"requestId,omitempty"`
+}
+
+type cError struct {
+	err error
+}
+
+type InvalidInputError cError
+
+type UnauthorizedError cError
+
+type ForbiddenError cError
+
+type NotFoundError cError
+
+type ConflictError cError
+
+type ServiceUnavailableError cError
+
+type PreconditionFailedError cError
+
+type PreconditionRequiredError cError
+
+type UnsupportedMediaTypeError cError
+
+func NewInvalidInputError(err error) error {
+	return &InvalidInputError{err: err}
+}
+
+func (e *InvalidInputError) Error() string {
+	return e.err.Error()
+}
+
+func (e *InvalidInputError) Unwrap() error {
+	return e.err
+}
+
+func NewUnauthorizedError(err error) error {
+	return &UnauthorizedError{err: err}
+}
+
+func (e *UnauthorizedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *UnauthorizedError) Unwrap() error {
+	return e.err
+}
+
+func NewForbiddenError(err error) error {
+	return &ForbiddenError{err: err}
+}
+
+func (e *ForbiddenError) Error() string {
+	return e.err.Error()
+}
+
+func (e *ForbiddenError) Unwrap() error {
+	return e.err
+}
+
+func NewNotFoundError(err error) error {
+	return &NotFoundError{err: err}
+}
+
+func (e *NotFoundError) Error() string {
+	return e.err.Error()
+}
+
+func (e *NotFoundError) Unwrap() error {
+	return e.err
+}
+
+func NewConflictError(err error) error {
+	return &ConflictError{err: err}
+}
+
+func (e *ConflictError) Error() string {
+	return e.err.Error()
+}
+
+func (e *ConflictError) Unwrap() error {
+	return e.err
+}
+
+func NewServiceUnavailableError(err error) error {
+	return &ServiceUnavailableError{err: err}
+}
+
+func (e *ServiceUnavailableError) Error() string {
+	return e.err.Error()
+}
+
+func (e *ServiceUnavailableError) Unwrap() error {
+	return e.err
+}
+
+func NewPreconditionFailedError(err error) error {
+	return &PreconditionFailedError{err: err}
+}
+
+func (e *PreconditionFailedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *PreconditionFailedError) Unwrap() error {
+	return e.err
+}
+
+func NewPreconditionRequiredError(err error) error {
+	return &PreconditionRequiredError{err: err}
+}
+
+func (e *PreconditionRequiredError) Error() string {
+	return e.err.Error()
+}
+
+func (e *PreconditionRequiredError) Unwrap() error {
+	return e.err
+}
+
+func NewUnsupportedMediaTypeError(err error) error {
+	return &UnsupportedMediaTypeError{err: err}
+}
+
+func (e *UnsupportedMediaTypeError) Error() string {
+	return e.err.Error()
+}
+
+func (e *UnsupportedMediaTypeError) Unwrap() error {
+	return e.err
+}
+
+// StatusCode returns the HTTP status code matching the error type, 0 if there is none.
+func StatusCode(err error) int {
+	var err1 *InvalidInputError
+	if errors.As(err, &err1) {
+		return http.StatusBadRequest
+	}
+	var err2 *UnauthorizedError
+	if errors.As(err, &err2) {
+		return http.StatusUnauthorized
+	}
+	var err3 *ForbiddenError
+	if errors.As(err, &err3) {
+		return http.StatusForbidden
+	}
+	var err4 *NotFoundError
+	if errors.As(err, &err4) {
+		return http.StatusNotFound
+	}
+	var err5 *ConflictError
+	if errors.As(err, &err5) {
+		return http.StatusConflict
+	}
+	var err6 *ServiceUnavailableError
+	if errors.As(err, &err6) {
+		return http.StatusServiceUnavailable
+	}
+	var err7 *PreconditionFailedError
+	if errors.As(err, &err7) {
+		return http.StatusPreconditionFailed
+	}
+	var err8 *PreconditionRequiredError
+	if errors.As(err, &err8) {
+		return http.StatusPreconditionRequired
+	}
+	var err9 *UnsupportedMediaTypeError
+	if errors.As(err, &err9) {
+		return http.StatusUnsupportedMediaType
+	}
+	return 0
+}
@@ -0,0 +1,121 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lib
+
+import (
+	"encoding/json"
+	"slices"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const EventTypePrefix = "org.senergy.analytics.operator."
+
+const (
+	EventTypeOperatorCreated            = EventTypePrefix + "created"
+	EventTypeOperatorUpdated            = EventTypePrefix + "updated"
+	EventTypeOperatorTrashed            = EventTypePrefix + "trashed"
+	EventTypeOperatorRestored           = EventTypePrefix + "restored"
+	EventTypeOperatorPurged             = EventTypePrefix + "purged"
+	EventTypeOperatorPermissionsChanged = EventTypePrefix + "permissions_changed"
+	// Deprecated: deleted operators are moved to the trash, see EventTypeOperatorTrashed and EventTypeOperatorPurged.
+	// The type remains for history entries recorded before.
+	EventTypeOperatorDeleted = EventTypePrefix + "deleted"
+)
+
+const EventSource = "analytics-operator-repo-v2"
+
+// OperatorEvent is a CloudEvents 1.0 event in structured JSON mode.
+type OperatorEvent struct {
+	SpecVersion     string            `bson:"specversion" json:"specversion"`
+	Id              string            `bson:"id" json:"id"`
+	Source          string            `bson:"source" json:"source"`
+	Type            string            `bson:"type" json:"type"`
+	Subject         string            `bson:"subject" json:"subject"`
+	Time            time.Time         `bson:"time" json:"time"`
+	DataContentType string            `bson:"datacontenttype" json:"datacontenttype"`
+	Data            OperatorEventData `bson:"data" json:"data"`
+}
+
+type OperatorEventData struct {
+	Id          string               `bson:"id" json:"id"`
+	UserId      string               `bson:"userId,omitempty" json:"userId,omitempty"`
+	RequestId   string               `bson:"requestId,omitempty" json:"requestId,omitempty"`
+	Operator    *Operator            `bson:"operator,omitempty" json:"operator,omitempty"`
+	Diff        []FieldChange        `bson:"diff,omitempty" json:"diff,omitempty"`
+	Permissions *OperatorPermissions `bson:"permissions,omitempty" json:"permissions,omitempty"`
+	// PreviousPermissions are the permissions before a permission change.
+	PreviousPermissions *OperatorPermissions `bson:"previousPermissions,omitempty" json:"previousPermissions,omitempty"`
+}
+
+type FieldChange struct {
+	Field string `bson:"field" json:"field"`
+	Old   any    `bson:"old,omitempty" json:"old,omitempty"`
+	New   any    `bson:"new,omitempty" json:"new,omitempty"`
+}
+
+func NewOperatorEvent(eventType string, id string, userId string) OperatorEvent {
+	return OperatorEvent{
+		SpecVersion:     "1.0",
+		Id:              bson.NewObjectID().Hex(),
+		Source:          EventSource,
+		Type:            eventType,
+		Subject:         id,
+		Time:            time.Now(),
+		DataContentType: "application/json",
+		Data: OperatorEventData{
+			Id:     id,
+			UserId: userId,
+		},
+	}
+}
+
+// DiffOperators lists the changed fields by their JSON name, timestamps are ignored.
+func DiffOperators(prev Operator, next Operator) (changes []FieldChange) {
+	oldFields := toJsonMap(prev)
+	newFields := toJsonMap(next)
+	var fields []string
+	for field := range oldFields {
+		fields = append(fields, field)
+	}
+	for field := range newFields {
+		if _, ok := oldFields[field]; !ok {
+			fields = append(fields, field)
+		}
+	}
+	slices.Sort(fields)
+	for _, field := range fields {
+		if field == "dateCreated" || field == "dateUpdated" || field == "revision" {
+			continue
+		}
+		if !jsonEqual(oldFields[field], newFields[field]) {
+			changes = append(changes, FieldChange{Field: field, Old: oldFields[field], New: newFields[field]})
+		}
+	}
+	return
+}
+
+func toJsonMap(operator Operator) map[string]any {
+	m := map[string]any{}
+	b, err := json.Marshal(operator)
+	if err != nil {
+		return m
+	}
+	_ = json.Unmarshal(b, &m)
+	return m
+}
@@ -0,0 +1,66 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lib
+
+import (
+	"strings"
+	"time"
+)
+
+// HistoryEntry records a single change of an operator.
+// The actor is empty for changes the service made on its own, e.g. after a user was deleted.
+type HistoryEntry struct {
+	Id                  string               `bson:"_id" json:"id"`
+	OperatorId          string               `bson:"operatorId" json:"operatorId"`
+	Action              string               `bson:"action" json:"action"`
+	Actor               string               `bson:"actor,omitempty" json:"actor,omitempty"`
+	Time                time.Time            `bson:"time" json:"time"`
+	RequestId           string               `bson:"requestId,omitempty" json:"requestId,omitempty"`
+	Diff                []FieldChange        `bson:"diff,omitempty" json:"diff,omitempty"`
+	Permissions         *OperatorPermissions `bson:"permissions,omitempty" json:"permissions,omitempty"`
+	PreviousPermissions *OperatorPermissions `bson:"previousPermissions,omitempty" json:"previousPermissions,omitempty"`
+}
+
+type HistoryResponse struct {
+	Entries []HistoryEntry `json:"entries"`
+	Total   int64          `json:"totalCount"`
+}
+
+// NewHistoryEntry converts an operator event into a history entry, the action is the event type without prefix.
+// Creations and restorations are recorded as changes from an empty operator, moves to the trash as changes to an empty operator.
+func NewHistoryEntry(event OperatorEvent) HistoryEntry {
+	entry := HistoryEntry{
+		Id:                  event.Id,
+		OperatorId:          event.Data.Id,
+		Action:              strings.TrimPrefix(event.Type, EventTypePrefix),
+		Actor:               event.Data.UserId,
+		Time:                event.Time,
+		RequestId:           event.Data.RequestId,
+		Diff:                event.Data.Diff,
+		Permissions:         event.Data.Permissions,
+		PreviousPermissions: event.Data.PreviousPermissions,
+	}
+	if event.Data.Operator != nil {
+		switch event.Type {
+		case EventTypeOperatorCreated, EventTypeOperatorRestored:
+			entry.Diff = DiffOperators(Operator{}, *event.Data.Operator)
+		case EventTypeOperatorTrashed, EventTypeOperatorDeleted:
+			entry.Diff = DiffOperators(*event.Data.Operator, Operator{})
+		}
+	}
+	return entry
+}
@@ -17,33 +17,165 @@
 package lib
 
 import (
+	"encoding/json"
 	"time"
 
 	"go.mongodb.org/mongo-driver/v2/bson"
 )
 
+const (
+	PatchTypeMerge = "application/merge-patch+json"
+	PatchTypeJSON  = "application/json-patch+json"
+)
+
+// AnyRevision matches every stored revision of an operator, it is used for an If-Match of *.
+const AnyRevision int64 = -1
+
 type OperatorResponse struct {
-	Operators []Operator `json:"operators"`
-	Total     int64      `json:"totalCount"`
+	Operators  []Operator                   `json:"operators"`
+	Total      int64                        `json:"totalCount"`
+	Highlights map[string][]SearchHighlight `json:"highlights,omitempty"`
+	Facets     map[string][]FacetCount      `json:"facets,omitempty"`
+	NextCursor string                       `json:"next_cursor,omitempty"`
+}
+
+// FacetCount is the number of operators with a value in a field, a missing value is null.
+type FacetCount struct {
+	Value any   `json:"value"`
+	Count int64 `json:"count"`
 }
 
+// Operator is an analytics operator. The published flag is only set by publishing it, the flag is ignored when an
+// operator is created or updated.
 type Operator struct {
 	Id             *bson.ObjectID `bson:"_id,omitempty" json:"_id,omitempty"`
 	Name           string         `json:"name,omitempty" binding:"required"`
 	Image          string         `json:"image,omitempty"`
 	Description    string         `json:"description,omitempty"`
+	Tags           []string       `bson:"tags,omitempty" json:"tags,omitempty"`
 	DeploymentType string         `bson:"deploymentType" json:"deploymentType,omitempty"`
 	Cost           *int64         `json:"cost,omitempty"`
 	UserId         string         `bson:"userId" json:"userId,omitempty"`
 	Pub            bool           `json:"pub,omitempty"`
-	Config         []Value        `bson:"config_values" json:"config_values,omitempty"`
+	Version        string         `bson:"version,omitempty" json:"version,omitempty"`
+	Published      bool           `bson:"published" json:"published"`
+	Revision       int64          `bson:"revision" json:"revision"`
+	Config         []ConfigValue  `bson:"config_values" json:"config_values,omitempty"`
 	Inputs         []Value        `json:"inputs,omitempty"`
 	Outputs        []Value        `json:"outputs,omitempty"`
 	DateCreated    time.Time      `bson:"dateCreated,omitempty" json:"dateCreated,omitempty"`
 	DateUpdated    time.Time      `bson:"dateUpdated,omitempty" json:"dateUpdated,omitempty"`
+	DateDeleted    *time.Time     `bson:"dateDeleted,omitempty" json:"dateDeleted,omitempty"`
+	DeletedBy      string         `bson:"deletedBy,omitempty" json:"deletedBy,omitempty"`
 }
 
 type Value struct {
 	Name string `json:"name"`
 	Type string `json:"type"`
 }
+
+type ConfigValue struct {
+	Name        string   `json:"name"`
+	Type        string   `json:"type"`
+	Description string   `bson:"description,omitempty" json:"description,omitempty"`
+	Default     any      `bson:"default,omitempty" json:"default,omitempty"`
+	Required    bool     `bson:"required,omitempty" json:"required,omitempty"`
+	Options     []any    `bson:"options,omitempty" json:"options,omitempty"`
+	Min         *float64 `bson:"min,omitempty" json:"min,omitempty"`
+	Max         *float64 `bson:"max,omitempty" json:"max,omitempty"`
+	Pattern     string   `bson:"pattern,omitempty" json:"pattern,omitempty"`
+	Secret      bool     `bson:"secret,omitempty" json:"secret,omitempty"`
+}
+
+type OperatorVersion struct {
+	OperatorId    string     `bson:"operatorId" json:"operatorId"`
+	Version       string     `bson:"version" json:"version"`
+	Published     bool       `bson:"published" json:"published"`
+	Operator      Operator   `bson:"operator" json:"operator"`
+	DateCreated   time.Time  `bson:"dateCreated" json:"dateCreated"`
+	DatePublished *time.Time `bson:"datePublished,omitempty" json:"datePublished,omitempty"`
+}
+
+type OperatorVersionsResponse struct {
+	Versions []OperatorVersion `json:"versions"`
+	Total    int64             `json:"totalCount"`
+}
+
+type ConfigValidationResponse struct {
+	Valid  bool               `json:"valid"`
+	Errors []ConfigFieldError `json:"errors"`
+}
+
+type ConfigFieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type OperatorPermissions struct {
+	UserPermissions  map[string]PermissionsMap `json:"user_permissions"`
+	GroupPermissions map[string]PermissionsMap `json:"group_permissions"`
+	RolePermissions  map[string]PermissionsMap `json:"role_permissions"`
+}
+
+type PermissionsMap struct {
+	Read         bool `json:"read"`
+	Write        bool `json:"write"`
+	Execute      bool `json:"execute"`
+	Administrate bool `json:"administrate"`
+}
+
+type TransferRequest struct {
+	UserId string `json:"userId" binding:"required"`
+}
+
+type ReassignRequest struct {
+	FromUserId string `json:"fromUserId" binding:"required"`
+	ToUserId   string `json:"toUserId" binding:"required"`
+}
+
+type ReassignResponse struct {
+	Transferred []string          `json:"transferred"`
+	Failed      map[string]string `json:"failed"`
+}
+
+type BatchGetRequest struct {
+	Ids []string `json:"ids" binding:"required"`
+}
+
+type BatchGetResponse struct {
+	Operators []Operator   `json:"operators"`
+	Results   []ItemStatus `json:"results"`
+}
+
+// BatchDeleteResponse reports the result of every operator of a batch deletion with an HTTP status code.
+// Either all operators are deleted or none, Deleted lists the operators that have been or, on a dry run, would be deleted.
+type BatchDeleteResponse struct {
+	DryRun  bool         `json:"dryRun"`
+	Deleted []string     `json:"deleted"`
+	Results []ItemStatus `json:"results"`
+}
+
+// BulkUpdateRequest selects operators either by ID list or by RSQL filter and applies a JSON merge patch to each of them.
+type BulkUpdateRequest struct {
+	Ids    []string        `json:"ids,omitempty"`
+	Filter string          `json:"filter,omitempty"`
+	Patch  json.RawMessage `json:"patch" binding:"required" swaggertype:"object"`
+}
+
+// BulkUpdateResponse counts the outcomes of a bulk update, the result of every operator is reported with an HTTP status code.
+// Operators the patch does not change are skipped with status 304.
+type BulkUpdateResponse struct {
+	Updated   int          `json:"updated"`
+	Skipped   int          `json:"skipped"`
+	Forbidden int          `json:"forbidden"`
+	Failed    int          `json:"failed"`
+	Results   []ItemStatus `json:"results"`
+}
+
+// ItemStatus reports the outcome for a single operator of a batch request with an HTTP status code.
+type ItemStatus struct {
+	Id     string `json:"id"`
+	Status int    `json:"status"`
+	Detail string `json:"detail,omitempty"`
+}
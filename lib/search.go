@@ -0,0 +1,86 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lib
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SearchHighlight names a field of an operator that matched the search and the matched terms.
+type SearchHighlight struct {
+	Field string   `json:"field"`
+	Value string   `json:"value"`
+	Terms []string `json:"terms"`
+}
+
+// SearchTerms splits a text search into lower case terms and phrases, negated terms are dropped.
+func SearchTerms(search string) (terms []string) {
+	var sb strings.Builder
+	quoted := false
+	flush := func() {
+		term := strings.ToLower(strings.TrimSpace(sb.String()))
+		sb.Reset()
+		if term != "" && !strings.HasPrefix(term, "-") {
+			terms = append(terms, term)
+		}
+	}
+	for _, r := range search {
+		switch {
+		case r == '"':
+			flush()
+			quoted = !quoted
+		case r == ' ' && !quoted:
+			flush()
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	flush()
+	return
+}
+
+// Highlights lists the searchable fields of the operator that contain at least one of the terms.
+func (o Operator) Highlights(terms []string) (highlights []SearchHighlight) {
+	check := func(field string, value string) {
+		lower := strings.ToLower(value)
+		var matched []string
+		for _, term := range terms {
+			if strings.Contains(lower, term) {
+				matched = append(matched, term)
+			}
+		}
+		if len(matched) > 0 {
+			highlights = append(highlights, SearchHighlight{Field: field, Value: value, Terms: matched})
+		}
+	}
+	check("name", o.Name)
+	check("description", o.Description)
+	for i, tag := range o.Tags {
+		check(fmt.Sprintf("tags[%d]", i), tag)
+	}
+	for i, input := range o.Inputs {
+		check(fmt.Sprintf("inputs[%d].name", i), input.Name)
+	}
+	for i, output := range o.Outputs {
+		check(fmt.Sprintf("outputs[%d].name", i), output.Name)
+	}
+	for i, value := range o.Config {
+		check(fmt.Sprintf("config_values[%d].name", i), value.Name)
+	}
+	return
+}
@@ -0,0 +1,149 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lib
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const InitialVersion = "1.0.0"
+
+// SemVer is a MAJOR.MINOR.PATCH version as described by https://semver.org.
+// Pre-release and build metadata are not supported.
+type SemVer struct {
+	Major uint64
+	Minor uint64
+	Patch uint64
+}
+
+func ParseSemVer(s string) (v SemVer, err error) {
+	parts := strings.Split(s, ".")
+	if len(parts) != 3 {
+		return v, NewInvalidInputError(fmt.Errorf("invalid version %q: expected MAJOR.MINOR.PATCH", s))
+	}
+	nums := make([]uint64, 3)
+	for i, part := range parts {
+		if part == "" || (len(part) > 1 && part[0] == '0') {
+			return v, NewInvalidInputError(fmt.Errorf("invalid version %q", s))
+		}
+		nums[i], err = strconv.ParseUint(part, 10, 64)
+		if err != nil {
+			return v, NewInvalidInputError(fmt.Errorf("invalid version %q", s))
+		}
+	}
+	return SemVer{Major: nums[0], Minor: nums[1], Patch: nums[2]}, nil
+}
+
+func (v SemVer) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// Compare returns -1, 0 or 1 if v is lower than, equal to or greater than o.
+func (v SemVer) Compare(o SemVer) int {
+	for _, d := range [][2]uint64{{v.Major, o.Major}, {v.Minor, o.Minor}, {v.Patch, o.Patch}} {
+		if d[0] < d[1] {
+			return -1
+		}
+		if d[0] > d[1] {
+			return 1
+		}
+	}
+	return 0
+}
+
+func (v SemVer) NextMajor() SemVer {
+	return SemVer{Major: v.Major + 1}
+}
+
+func (v SemVer) NextMinor() SemVer {
+	return SemVer{Major: v.Major, Minor: v.Minor + 1}
+}
+
+func (v SemVer) NextPatch() SemVer {
+	return SemVer{Major: v.Major, Minor: v.Minor, Patch: v.Patch + 1}
+}
+
+// CheckVersionBump validates that next is a valid successor of prev for the given operator interfaces.
+// Changes that break pipelines built against prev are only allowed with a major bump.
+func CheckVersionBump(prev SemVer, prevOperator Operator, next SemVer, nextOperator Operator) error {
+	if next.Compare(prev) <= 0 {
+		return NewConflictError(fmt.Errorf("version %s must be greater than %s", next, prev))
+	}
+	if next.Major == prev.Major && !InterfaceCompatible(prevOperator, nextOperator) {
+		return NewConflictError(errors.New("inputs or outputs changed incompatibly, version " + next.String() + " must be a major bump of " + prev.String()))
+	}
+	return nil
+}
+
+// NextVersion returns the smallest version bump that is valid for the given interface change.
+func NextVersion(prev SemVer, prevOperator Operator, nextOperator Operator) SemVer {
+	if !InterfaceCompatible(prevOperator, nextOperator) {
+		return prev.NextMajor()
+	}
+	if !valuesEqual(prevOperator.Outputs, nextOperator.Outputs) || !valuesEqual(prevOperator.Inputs, nextOperator.Inputs) {
+		return prev.NextMinor()
+	}
+	return prev.NextPatch()
+}
+
+// InterfaceCompatible reports whether pipelines built against prev keep working with next.
+// Inputs must stay the same, outputs may only be added.
+func InterfaceCompatible(prev Operator, next Operator) bool {
+	if !valuesEqual(prev.Inputs, next.Inputs) {
+		return false
+	}
+	nextOutputs := valueTypes(next.Outputs)
+	for _, output := range prev.Outputs {
+		if t, ok := nextOutputs[output.Name]; !ok || t != output.Type {
+			return false
+		}
+	}
+	return true
+}
+
+// VersionedContentEqual reports whether the fields covered by a version are unchanged.
+func VersionedContentEqual(a Operator, b Operator) bool {
+	return a.Image == b.Image &&
+		a.DeploymentType == b.DeploymentType &&
+		valuesEqual(a.Inputs, b.Inputs) &&
+		valuesEqual(a.Outputs, b.Outputs) &&
+		jsonEqual(a.Config, b.Config)
+}
+
+func valuesEqual(a []Value, b []Value) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	bTypes := valueTypes(b)
+	for _, value := range a {
+		if t, ok := bTypes[value.Name]; !ok || t != value.Type {
+			return false
+		}
+	}
+	return true
+}
+
+func valueTypes(values []Value) map[string]string {
+	types := make(map[string]string, len(values))
+	for _, value := range values {
+		types[value.Name] = value.Type
+	}
+	return types
+}
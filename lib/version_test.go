@@ -0,0 +1,163 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lib
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseSemVer(t *testing.T) {
+	cases := []struct {
+		version  string
+		expected SemVer
+		valid    bool
+	}{
+		{"1.0.0", SemVer{Major: 1}, true},
+		{"0.1.2", SemVer{Minor: 1, Patch: 2}, true},
+		{"10.20.30", SemVer{Major: 10, Minor: 20, Patch: 30}, true},
+		{"", SemVer{}, false},
+		{"1.0", SemVer{}, false},
+		{"1.0.0.0", SemVer{}, false},
+		{"1..0", SemVer{}, false},
+		{"01.0.0", SemVer{}, false},
+		{"1.0.0-rc1", SemVer{}, false},
+		{"v1.0.0", SemVer{}, false},
+		{"-1.0.0", SemVer{}, false},
+		{"1.0.99999999999999999999", SemVer{}, false},
+	}
+	for _, c := range cases {
+		t.Run(c.version, func(t *testing.T) {
+			v, err := ParseSemVer(c.version)
+			if !c.valid {
+				var invalid *InvalidInputError
+				if !errors.As(err, &invalid) {
+					t.Fatalf("expected invalid input error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if v != c.expected {
+				t.Errorf("expected %v, got %v", c.expected, v)
+			}
+			if v.String() != c.version {
+				t.Errorf("expected %s, got %s", c.version, v.String())
+			}
+		})
+	}
+}
+
+var (
+	versionInputs  = []Value{{Name: "value", Type: "float"}}
+	versionOutputs = []Value{{Name: "result", Type: "float"}}
+)
+
+func versionOperator(inputs []Value, outputs []Value) Operator {
+	return Operator{Inputs: inputs, Outputs: outputs}
+}
+
+func TestInterfaceCompatible(t *testing.T) {
+	prev := versionOperator(versionInputs, versionOutputs)
+	cases := []struct {
+		name     string
+		next     Operator
+		expected bool
+	}{
+		{"unchanged", versionOperator(versionInputs, versionOutputs), true},
+		{"reordered outputs", versionOperator(versionInputs, []Value{{Name: "count", Type: "integer"}, {Name: "result", Type: "float"}}), true},
+		{"added output", versionOperator(versionInputs, append([]Value{{Name: "count", Type: "integer"}}, versionOutputs...)), true},
+		{"removed output", versionOperator(versionInputs, nil), false},
+		{"changed output type", versionOperator(versionInputs, []Value{{Name: "result", Type: "string"}}), false},
+		{"renamed output", versionOperator(versionInputs, []Value{{Name: "res", Type: "float"}}), false},
+		{"added input", versionOperator(append([]Value{{Name: "ts", Type: "string"}}, versionInputs...), versionOutputs), false},
+		{"removed input", versionOperator(nil, versionOutputs), false},
+		{"changed input type", versionOperator([]Value{{Name: "value", Type: "integer"}}, versionOutputs), false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if compatible := InterfaceCompatible(prev, c.next); compatible != c.expected {
+				t.Errorf("expected %v, got %v", c.expected, compatible)
+			}
+		})
+	}
+}
+
+func TestNextVersion(t *testing.T) {
+	prev := versionOperator(versionInputs, versionOutputs)
+	cases := []struct {
+		name     string
+		next     Operator
+		expected string
+	}{
+		{"unchanged", versionOperator(versionInputs, versionOutputs), "1.2.4"},
+		{"added output", versionOperator(versionInputs, append([]Value{{Name: "count", Type: "integer"}}, versionOutputs...)), "1.3.0"},
+		{"removed output", versionOperator(versionInputs, nil), "2.0.0"},
+		{"changed input", versionOperator([]Value{{Name: "value", Type: "integer"}}, versionOutputs), "2.0.0"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			next := NextVersion(SemVer{Major: 1, Minor: 2, Patch: 3}, prev, c.next)
+			if next.String() != c.expected {
+				t.Errorf("expected %s, got %s", c.expected, next)
+			}
+		})
+	}
+}
+
+func TestCheckVersionBump(t *testing.T) {
+	prev := versionOperator(versionInputs, versionOutputs)
+	compatible := versionOperator(versionInputs, append([]Value{{Name: "count", Type: "integer"}}, versionOutputs...))
+	breaking := versionOperator(versionInputs, nil)
+	cases := []struct {
+		name  string
+		next  string
+		op    Operator
+		valid bool
+	}{
+		{"patch", "1.2.4", prev, true},
+		{"minor", "1.3.0", compatible, true},
+		{"skipped versions", "1.5.0", compatible, true},
+		{"major", "2.0.0", breaking, true},
+		{"major without change", "2.0.0", prev, true},
+		{"same", "1.2.3", prev, false},
+		{"lower", "1.2.2", prev, false},
+		{"lower major", "0.9.0", prev, false},
+		{"breaking minor", "1.3.0", breaking, false},
+		{"breaking patch", "1.2.4", breaking, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			next, err := ParseSemVer(c.next)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = CheckVersionBump(SemVer{Major: 1, Minor: 2, Patch: 3}, prev, next, c.op)
+			if c.valid {
+				if err != nil {
+					t.Errorf("expected valid bump, got %v", err)
+				}
+				return
+			}
+			var conflict *ConflictError
+			if !errors.As(err, &conflict) {
+				t.Errorf("expected conflict error, got %v", err)
+			}
+		})
+	}
+}
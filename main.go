@@ -30,6 +30,7 @@ import (
 	"github.com/SENERGY-Platform/analytics-operator-repo-v2/pkg/api"
 	"github.com/SENERGY-Platform/analytics-operator-repo-v2/pkg/config"
 	"github.com/SENERGY-Platform/analytics-operator-repo-v2/pkg/db"
+	"github.com/SENERGY-Platform/analytics-operator-repo-v2/pkg/events"
 	"github.com/SENERGY-Platform/analytics-operator-repo-v2/pkg/service"
 	"github.com/SENERGY-Platform/analytics-operator-repo-v2/pkg/util"
 	"github.com/SENERGY-Platform/go-service-base/srv-info-hdl"
@@ -81,13 +82,34 @@ func main() {
 		perm = permV2Client.New(cfg.PermissionsV2Url)
 	}
 
-	srv, err := service.New(*srvInfoHdl, perm, *database)
+	srv, err := service.New(*srvInfoHdl, perm, *database, cfg)
 	if err != nil {
 		util.Logger.Error("error on new service", "error", err)
 		ec = 1
 		return
 	}
 
+	if cfg.ReadersSyncInterval > 0 {
+		go srv.RunReadersSync(ctx, cfg.ReadersSyncInterval)
+	}
+
+	if cfg.TrashRetention > 0 && cfg.TrashPurgeInterval > 0 {
+		go srv.RunTrashPurger(ctx, cfg.TrashPurgeInterval, cfg.TrashRetention)
+	}
+
+	if cfg.OperatorEvents.Enabled {
+		publisher := events.NewKafkaPublisher(cfg.KafkaBootstrap)
+		defer publisher.Close()
+		outbox := events.NewOutbox(database.OutboxCollection(), database.OperatorCollection(), publisher, cfg.OperatorEvents.Topic, cfg.OperatorEvents.RetryInterval, cfg.OperatorEvents.RecoveryInterval)
+		if err = outbox.Init(); err != nil {
+			util.Logger.Error("error on init operator event outbox", "error", err)
+			ec = 1
+			return
+		}
+		srv.AddEventHandler(outbox)
+		go outbox.Run(ctx)
+	}
+
 	httpHandler, err := api.New(*srv, map[string]string{
 		api.HeaderApiVer:  srvInfoHdl.Version(),
 		api.HeaderSrvName: srvInfoHdl.Name(),
@@ -142,5 +164,19 @@ func main() {
 		}
 	}()
 
+	if cfg.UserEvents.Enabled {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			util.Logger.Info("starting user event consumer")
+			subscriber := events.NewKafkaSubscriber(cfg.KafkaBootstrap, cfg.UserEvents.GroupId)
+			if err := events.ConsumeUserEvents(ctx, subscriber, cfg.UserEvents.Topic, srv); err != nil {
+				util.Logger.Error("user event consumer failed", attributes.ErrorKey, err)
+				ec = 1
+				cf()
+			}
+		}()
+	}
+
 	wg.Wait()
 }
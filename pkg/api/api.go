@@ -18,10 +18,10 @@ package api
 
 import (
 	"errors"
-	"net/http"
 	"slices"
 	"strings"
 
+	"github.com/SENERGY-Platform/analytics-operator-repo-v2/lib"
 	"github.com/SENERGY-Platform/analytics-operator-repo-v2/pkg/service"
 	"github.com/SENERGY-Platform/analytics-operator-repo-v2/pkg/util"
 	"github.com/SENERGY-Platform/go-service-base/struct-logger/attributes"
@@ -46,9 +46,9 @@ func New(srv service.Service, staticHeader map[string]string, urlPrefix string)
 	httpHandler.RedirectTrailingSlash = false
 	httpHandler.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"*"},
-		AllowMethods:     []string{"GET", "POST", "DELETE", "OPTIONS", "PUT"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowMethods:     []string{"GET", "POST", "DELETE", "OPTIONS", "PUT", "PATCH"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", HeaderLastEventID, HeaderIdempotencyKey, HeaderIfMatch, HeaderIfNoneMatch},
+		ExposeHeaders:    []string{"Content-Length", HeaderLocation, HeaderETag},
 		AllowCredentials: true,
 	}))
 	var middleware []gin.HandlerFunc
@@ -63,9 +63,7 @@ func New(srv service.Service, staticHeader map[string]string, urlPrefix string)
 	)
 	middleware = append(middleware,
 		requestid.New(requestid.WithCustomHeaderStrKey(HeaderRequestID)),
-		gin_mw.ErrorHandler(func(err error) int {
-			return 0
-		}, ", "),
+		ErrorHandler(GetStatusCode, ", "),
 		gin_mw.StructRecoveryHandler(util.Logger, gin_mw.DefaultRecoveryFunc),
 	)
 	httpHandler.Use(middleware...)
@@ -94,7 +92,8 @@ func AuthMiddleware() gin.HandlerFunc {
 		userId, err := getUserId(gc)
 		if err != nil {
 			util.Logger.Error("could not get user id")
-			gc.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			_ = gc.Error(lib.NewUnauthorizedError(errors.New("unauthorized")))
+			gc.Abort()
 			return
 		}
 		gc.Set(UserIdKey, userId)
@@ -105,8 +104,7 @@ func AuthMiddleware() gin.HandlerFunc {
 func getUserId(c *gin.Context) (userId string, err error) {
 	forUser := c.Query("for_user")
 	if forUser != "" {
-		roles := strings.Split(c.GetHeader("X-User-Roles"), ", ")
-		if slices.Contains[[]string](roles, "admin") {
+		if isAdmin(c) {
 			return forUser, nil
 		}
 	}
@@ -126,3 +124,9 @@ func getUserId(c *gin.Context) (userId string, err error) {
 	}
 	return
 }
+
+// isAdmin trusts the roles header set by the gateway only, the token is not verified by this service.
+func isAdmin(c *gin.Context) bool {
+	roles := strings.Split(c.GetHeader(HeaderUserRoles), ", ")
+	return slices.Contains[[]string](roles, "admin")
+}
@@ -16,18 +16,36 @@
 
 package api
 
+import "time"
+
 const (
-	HeaderRequestID     = "X-Request-ID"
-	HeaderApiVer        = "X-Api-Version"
-	HeaderSrvName       = "X-Service"
-	HeaderAuthorization = "Authorization"
-	UserIdKey           = "UserId"
+	HeaderRequestID      = "X-Request-ID"
+	HeaderApiVer         = "X-Api-Version"
+	HeaderSrvName        = "X-Service"
+	HeaderAuthorization  = "Authorization"
+	HeaderUserRoles      = "X-User-Roles"
+	HeaderLastEventID    = "Last-Event-ID"
+	HeaderLocation       = "Location"
+	HeaderIdempotencyKey = "Idempotency-Key"
+	HeaderETag           = "ETag"
+	HeaderIfMatch        = "If-Match"
+	HeaderIfNoneMatch    = "If-None-Match"
+	UserIdKey            = "UserId"
 )
 
 const (
 	HealthCheckPath = "/health-check"
 )
 
+const (
+	StreamKeepAliveInterval = 30 * time.Second
+)
+
 const (
 	MessageSomethingWrong = "something went wrong"
+	MessageAdminRequired  = "admin role required"
+)
+
+const (
+	ContentTypeProblemJSON = "application/problem+json"
 )
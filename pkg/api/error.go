@@ -0,0 +1,65 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/SENERGY-Platform/analytics-operator-repo-v2/lib"
+	"github.com/gin-contrib/requestid"
+	"github.com/gin-gonic/gin"
+)
+
+func GetStatusCode(err error) int {
+	return lib.StatusCode(err)
+}
+
+// ErrorHandler works like gin_mw.ErrorHandler but responds with RFC 7807 problem details.
+// Details of server errors are not exposed to the client.
+func ErrorHandler(f func(error) int, sep string) gin.HandlerFunc {
+	return func(gc *gin.Context) {
+		gc.Next()
+		if gc.Writer.Written() || len(gc.Errors) == 0 {
+			return
+		}
+		status := gc.Writer.Status()
+		var details []string
+		for _, e := range gc.Errors {
+			if sc := f(e.Err); sc != 0 {
+				status = sc
+			}
+			details = append(details, e.Error())
+		}
+		if status < 400 {
+			status = http.StatusInternalServerError
+		}
+		detail := strings.Join(details, sep)
+		if status >= 500 {
+			detail = MessageSomethingWrong
+		}
+		gc.Header("Content-Type", ContentTypeProblemJSON)
+		gc.JSON(status, lib.ProblemDetails{
+			Type:      "about:blank",
+			Title:     http.StatusText(status),
+			Status:    status,
+			Detail:    detail,
+			Instance:  gc.Request.URL.Path,
+			RequestId: requestid.Get(gc),
+		})
+	}
+}
@@ -0,0 +1,64 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/SENERGY-Platform/analytics-operator-repo-v2/lib"
+)
+
+// etag formats the revision of an operator as strong entity tag.
+func etag(revision int64) string {
+	return strconv.Quote(strconv.FormatInt(revision, 10))
+}
+
+// parseIfMatch returns the revision required by an If-Match header, nil if the header is missing.
+// A header of * matches any revision.
+func parseIfMatch(header string) (*int64, error) {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return nil, nil
+	}
+	if header == "*" {
+		revision := lib.AnyRevision
+		return &revision, nil
+	}
+	tag := strings.TrimPrefix(header, "W/")
+	if value, err := strconv.Unquote(tag); err == nil {
+		tag = value
+	}
+	revision, err := strconv.ParseInt(tag, 10, 64)
+	if err != nil || revision < 1 {
+		return nil, lib.NewInvalidInputError(errors.New("invalid If-Match header: " + header))
+	}
+	return &revision, nil
+}
+
+// matchesIfNoneMatch reports whether an If-None-Match header lists the given entity tag.
+// Weak comparison is used as required for GET requests.
+func matchesIfNoneMatch(header string, tag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == tag {
+			return true
+		}
+	}
+	return false
+}
@@ -18,12 +18,18 @@ package api
 
 import (
 	"errors"
+	"io"
 	"net/http"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/SENERGY-Platform/analytics-operator-repo-v2/lib"
 	"github.com/SENERGY-Platform/analytics-operator-repo-v2/pkg/service"
 	"github.com/SENERGY-Platform/analytics-operator-repo-v2/pkg/util"
+	"github.com/gin-contrib/requestid"
+	"github.com/gin-contrib/sse"
 	"github.com/gin-gonic/gin"
 )
 
@@ -32,8 +38,18 @@ import (
 // @Description	Gets all operators
 // @Tags Operator
 // @Produce json
+// @Param search query string false "Full-text search over name, description, tags and input, output and config names, matches are highlighted"
+// @Param filter query string false "RSQL filter, e.g. deploymentType==cloud;cost=lt=10;inputs.name=in=(value,ts). Fields: name, deploymentType, pub, userId, cost, tags, version, published, dateCreated, dateUpdated, inputs.name, inputs.type, outputs.name, outputs.type, config_values.name"
+// @Param facets query string false "Comma separated fields to count values of over the whole result, e.g. deploymentType,pub,inputs.type. Fields: deploymentType, pub, published, userId, tags, inputs.name, inputs.type, outputs.name, outputs.type, config_values.name"
+// @Param shared query bool false "Only operators other users shared with the requesting user"
+// @Param limit query int false "Limit"
+// @Param offset query int false "Offset"
+// @Param fields query string false "Comma separated fields to return, the id and sort keys are always included"
+// @Param view query string false "Predefined projection, summary leaves out inputs, outputs and config values"
+// @Param cursor query string false "Continue after the page that returned this next_cursor, can not be combined with offset"
+// @Param sort query string false "Comma separated sort keys with optional direction, e.g. pub:desc,name:asc. Fields: name, dateCreated, dateUpdated, cost, deploymentType, owner, pub, published and relevance, which is the default when searching. Names are compared case-insensitively unless searching"
 // @Success	200 {object} lib.OperatorResponse
-// @Failure	500 {string} str
+// @Failure	400,500,503 {object} lib.ProblemDetails
 // @Router /operator [get]
 func getAll(srv service.Service) (string, string, gin.HandlerFunc) {
 	return http.MethodGet, "/operator", func(gc *gin.Context) {
@@ -41,7 +57,7 @@ func getAll(srv service.Service) (string, string, gin.HandlerFunc) {
 		flows, err := srv.GetOperators(gc.GetString(UserIdKey), args, gc.GetHeader("Authorization"))
 		if err != nil {
 			util.Logger.Error("error getting operators", "error", err)
-			_ = gc.Error(errors.New(MessageSomethingWrong))
+			_ = gc.Error(err)
 			return
 		}
 		gc.JSON(http.StatusOK, flows)
@@ -54,15 +70,56 @@ func getAll(srv service.Service) (string, string, gin.HandlerFunc) {
 // @Tags Operator
 // @Produce json
 // @Param id path string true "Operator ID"
+// @Param fields query string false "Comma separated fields to return, the id is always included"
+// @Param view query string false "Predefined projection, summary leaves out inputs, outputs and config values"
+// @Param If-None-Match header string false "ETag of a cached revision"
 // @Success	200 {object} lib.Operator
-// @Failure	500 {string} str
+// @Header 200 {string} ETag "Revision of the operator"
+// @Success	304 "Cached revision is current"
+// @Failure	400,403,404,500,503 {object} lib.ProblemDetails
 // @Router /operator/{id} [get]
 func getOperator(srv service.Service) (string, string, gin.HandlerFunc) {
 	return http.MethodGet, "/operator/:id", func(gc *gin.Context) {
-		resp, err := srv.GetOperator(gc.Param("id"), gc.GetString(UserIdKey), gc.GetHeader("Authorization"))
+		resp, err := srv.GetOperator(gc.Param("id"), gc.GetString(UserIdKey), gc.Request.URL.Query(), gc.GetHeader("Authorization"))
 		if err != nil {
 			util.Logger.Error("error getting operator", "error", err)
-			_ = gc.Error(errors.New(MessageSomethingWrong))
+			_ = gc.Error(err)
+			return
+		}
+		tag := etag(resp.Revision)
+		gc.Header(HeaderETag, tag)
+		if matchesIfNoneMatch(gc.GetHeader(HeaderIfNoneMatch), tag) {
+			gc.Status(http.StatusNotModified)
+			return
+		}
+		gc.JSON(http.StatusOK, resp)
+	}
+}
+
+// postBatchGetOperators godoc
+// @Summary Get multiple operators
+// @Description	Gets all readable operators of an ID list, the result of every ID is reported with an HTTP status code
+// @Tags Operator
+// @Accept json
+// @Produce json
+// @Param request body lib.BatchGetRequest true "ID list"
+// @Param fields query string false "Comma separated fields to return, the id is always included"
+// @Param view query string false "Predefined projection, summary leaves out inputs, outputs and config values"
+// @Success	200 {object} lib.BatchGetResponse
+// @Failure	400,500,503 {object} lib.ProblemDetails
+// @Router /operator/batch-get [post]
+func postBatchGetOperators(srv service.Service) (string, string, gin.HandlerFunc) {
+	return http.MethodPost, "/operator/batch-get", func(gc *gin.Context) {
+		var request lib.BatchGetRequest
+		if err := gc.ShouldBindJSON(&request); err != nil {
+			util.Logger.Error("error getting operators", "error", err)
+			_ = gc.Error(lib.NewInvalidInputError(err))
+			return
+		}
+		resp, err := srv.BatchGetOperators(request.Ids, gc.GetString(UserIdKey), gc.Request.URL.Query(), gc.GetHeader("Authorization"))
+		if err != nil {
+			util.Logger.Error("error getting operators", "error", err)
+			_ = gc.Error(err)
 			return
 		}
 		gc.JSON(http.StatusOK, resp)
@@ -71,73 +128,157 @@ func getOperator(srv service.Service) (string, string, gin.HandlerFunc) {
 
 // putOperator godoc
 // @Summary Create operator
-// @Description	Stores an operator
+// @Description	Stores an operator and returns it, the Location header points to the new operator. Requests repeated with the same Idempotency-Key return the operator created first.
 // @Tags Operator
 // @Param operator body lib.Operator true "Create operator"
+// @Param Idempotency-Key header string false "Unique key of the request"
 // @Accept json
-// @Success	201
-// @Failure	500 {string} str
+// @Produce json
+// @Success	201 {object} lib.Operator
+// @Header 201 {string} Location "Path of the created operator"
+// @Failure	400,403,404,409,500,503 {object} lib.ProblemDetails
 // @Router /operator/ [put]
 func putOperator(srv service.Service) (string, string, gin.HandlerFunc) {
 	return http.MethodPut, "/operator/", func(gc *gin.Context) {
 		var request lib.Operator
 		if err := gc.ShouldBindJSON(&request); err != nil {
 			util.Logger.Error("error creating operator", "error", err)
-			_ = gc.Error(errors.New(MessageSomethingWrong))
+			_ = gc.Error(lib.NewInvalidInputError(err))
 			return
 		}
-		err := srv.CreateOperator(request, gc.GetString(UserIdKey))
+		resp, err := srv.WithRequestId(requestid.Get(gc)).CreateOperator(request, gc.GetString(UserIdKey), gc.GetHeader(HeaderIdempotencyKey), gc.GetHeader("Authorization"))
 		if err != nil {
 			util.Logger.Error("error creating operator", "error", err)
-			_ = gc.Error(errors.New(MessageSomethingWrong))
+			_ = gc.Error(err)
 			return
 		}
-		gc.Status(http.StatusCreated)
+		gc.Header(HeaderLocation, strings.TrimSuffix(gc.Request.URL.Path, "/")+"/"+resp.Id.Hex())
+		gc.JSON(http.StatusCreated, resp)
 	}
 }
 
 // postOperator godoc
 // @Summary Update operator
-// @Description	Validates and updates an operator
+// @Description	Validates and updates an operator. With If-Match, the update is only applied to the given revision.
 // @Tags Operator
 // @Accept json
 // @Param id path string true "Operator ID"
 // @Param operator body lib.Operator true "Update operator"
+// @Param If-Match header string false "ETag of the revision to update, required if configured"
 // @Success	200
-// @Failure	500 {string} str
+// @Failure	400,403,404,409,412,428,500,503 {object} lib.ProblemDetails
 // @Router /operator/{id} [post]
 func postOperator(srv service.Service) (string, string, gin.HandlerFunc) {
 	return http.MethodPost, "/operator/:id/", func(gc *gin.Context) {
 		var request lib.Operator
 		if err := gc.ShouldBindJSON(&request); err != nil {
 			util.Logger.Error("error updating operator", "error", err)
-			_ = gc.Error(errors.New(MessageSomethingWrong))
+			_ = gc.Error(lib.NewInvalidInputError(err))
 			return
 		}
-		err := srv.UpdateOperator(gc.Param("id"), request, gc.GetString(UserIdKey), gc.GetHeader("Authorization"))
+		revision, err := parseIfMatch(gc.GetHeader(HeaderIfMatch))
 		if err != nil {
 			util.Logger.Error("error updating operator", "error", err)
-			_ = gc.Error(errors.New(MessageSomethingWrong))
+			_ = gc.Error(err)
+			return
+		}
+		err = srv.WithRequestId(requestid.Get(gc)).UpdateOperator(gc.Param("id"), request, gc.GetString(UserIdKey), revision, gc.GetHeader("Authorization"))
+		if err != nil {
+			util.Logger.Error("error updating operator", "error", err)
+			_ = gc.Error(err)
 			return
 		}
 		gc.Status(http.StatusOK)
 	}
 }
 
+// patchOperator godoc
+// @Summary Patch operator
+// @Description	Partially updates an operator with a JSON merge patch (RFC 7396) or a JSON patch (RFC 6902) and returns the result. The patched operator is validated before it is stored.
+// @Tags Operator
+// @Accept application/merge-patch+json,application/json-patch+json
+// @Produce json
+// @Param id path string true "Operator ID"
+// @Param patch body object true "Merge patch or list of patch operations"
+// @Param If-Match header string false "ETag of the revision to patch, required if configured"
+// @Success	200 {object} lib.Operator
+// @Header 200 {string} ETag "Revision of the operator"
+// @Failure	400,403,404,409,412,415,428,500,503 {object} lib.ProblemDetails
+// @Router /operator/{id} [patch]
+func patchOperator(srv service.Service) (string, string, gin.HandlerFunc) {
+	return http.MethodPatch, "/operator/:id", func(gc *gin.Context) {
+		patch, err := io.ReadAll(gc.Request.Body)
+		if err != nil {
+			util.Logger.Error("error patching operator", "error", err)
+			_ = gc.Error(lib.NewInvalidInputError(err))
+			return
+		}
+		revision, err := parseIfMatch(gc.GetHeader(HeaderIfMatch))
+		if err != nil {
+			util.Logger.Error("error patching operator", "error", err)
+			_ = gc.Error(err)
+			return
+		}
+		resp, err := srv.WithRequestId(requestid.Get(gc)).PatchOperator(gc.Param("id"), gc.ContentType(), patch, gc.GetString(UserIdKey), revision, gc.GetHeader("Authorization"))
+		if err != nil {
+			util.Logger.Error("error patching operator", "error", err)
+			_ = gc.Error(err)
+			return
+		}
+		gc.Header(HeaderETag, etag(resp.Revision))
+		gc.JSON(http.StatusOK, resp)
+	}
+}
+
+// postBulkUpdateOperators godoc
+// @Summary Update multiple operators
+// @Description	Applies a JSON merge patch to all operators of an ID list or matching an RSQL filter the user may write. The result of every operator is reported with an HTTP status code, operators the patch does not change are skipped with status 304.
+// @Tags Operator
+// @Accept json
+// @Produce json
+// @Param request body lib.BulkUpdateRequest true "ID list or filter and patch"
+// @Success	207 {object} lib.BulkUpdateResponse
+// @Failure	400,500,503 {object} lib.ProblemDetails
+// @Router /operator/bulk-update [post]
+func postBulkUpdateOperators(srv service.Service) (string, string, gin.HandlerFunc) {
+	return http.MethodPost, "/operator/bulk-update", func(gc *gin.Context) {
+		var request lib.BulkUpdateRequest
+		if err := gc.ShouldBindJSON(&request); err != nil {
+			util.Logger.Error("error updating operators", "error", err)
+			_ = gc.Error(lib.NewInvalidInputError(err))
+			return
+		}
+		resp, err := srv.WithRequestId(requestid.Get(gc)).BulkUpdateOperators(request, gc.GetString(UserIdKey), gc.GetHeader("Authorization"))
+		if err != nil {
+			util.Logger.Error("error updating operators", "error", err)
+			_ = gc.Error(err)
+			return
+		}
+		gc.JSON(http.StatusMultiStatus, resp)
+	}
+}
+
 // deleteOperator godoc
 // @Summary Delete operator
-// @Description	Deletes an operator
+// @Description	Moves an operator to the trash and removes its permissions, it can be restored until the trash is purged. With If-Match, only the given revision is deleted.
 // @Tags Operator
 // @Param id path string true "Operator ID"
+// @Param If-Match header string false "ETag of the revision to delete, required if configured"
 // @Success	204
-// @Failure	500 {string} str
+// @Failure	400,403,404,412,428,500,503 {object} lib.ProblemDetails
 // @Router /operator/{id} [delete]
 func deleteOperator(srv service.Service) (string, string, gin.HandlerFunc) {
 	return http.MethodDelete, "/operator/:id/", func(gc *gin.Context) {
-		err := srv.DeleteOperator(gc.Param("id"), gc.GetString(UserIdKey), gc.GetHeader("Authorization"))
+		revision, err := parseIfMatch(gc.GetHeader(HeaderIfMatch))
+		if err != nil {
+			util.Logger.Error("error deleting operator", "error", err)
+			_ = gc.Error(err)
+			return
+		}
+		err = srv.WithRequestId(requestid.Get(gc)).DeleteOperator(gc.Param("id"), gc.GetString(UserIdKey), revision, gc.GetHeader("Authorization"))
 		if err != nil {
 			util.Logger.Error("error deleting operator", "error", err)
-			_ = gc.Error(errors.New(MessageSomethingWrong))
+			_ = gc.Error(err)
 			return
 		}
 		gc.Status(http.StatusNoContent)
@@ -146,32 +287,385 @@ func deleteOperator(srv service.Service) (string, string, gin.HandlerFunc) {
 
 // deleteOperators godoc
 // @Summary Delete multiple operators
-// @Description	Deletes multiple operators
+// @Description	Moves all operators of an ID list to the trash or none of them. The result of every operator is reported with an HTTP status code, operators that were not deleted because another one failed are reported with status 424.
 // @Tags Operator
 // @Accept json
+// @Produce json
 // @Param request body []string true "ID list"
-// @Success	204
-// @Failure	500 {string} str
+// @Param dryRun query bool false "Only report which operators would be deleted"
+// @Success	207 {object} lib.BatchDeleteResponse
+// @Failure	400,500,503 {object} lib.ProblemDetails
 // @Router /operator [delete]
 func deleteOperators(srv service.Service) (string, string, gin.HandlerFunc) {
 	return http.MethodDelete, "/operator", func(gc *gin.Context) {
 		var request []string
 		if err := gc.ShouldBindJSON(&request); err != nil {
 			util.Logger.Error("error deleting operators", "error", err)
-			_ = gc.Error(errors.New(MessageSomethingWrong))
+			_ = gc.Error(lib.NewInvalidInputError(err))
 			return
 		}
-
-		err := srv.DeleteOperators(request, gc.GetString(UserIdKey), gc.GetHeader("Authorization"))
+		dryRun, _ := strconv.ParseBool(gc.Query("dryRun"))
+		resp, err := srv.WithRequestId(requestid.Get(gc)).DeleteOperators(request, gc.GetString(UserIdKey), dryRun, gc.GetHeader("Authorization"))
 		if err != nil {
 			util.Logger.Error("error deleting operators", "error", err)
-			_ = gc.Error(errors.New(MessageSomethingWrong))
+			_ = gc.Error(err)
+			return
+		}
+		gc.JSON(http.StatusMultiStatus, resp)
+	}
+}
+
+// getTrashedOperators godoc
+// @Summary Get deleted operators
+// @Description	Gets the operators in the trash the user owns or deleted, latest deletion first. Admins get all.
+// @Tags Operator
+// @Produce json
+// @Param limit query int false "Limit"
+// @Param offset query int false "Offset"
+// @Success	200 {object} lib.OperatorResponse
+// @Failure	500,503 {object} lib.ProblemDetails
+// @Router /operator/trash [get]
+func getTrashedOperators(srv service.Service) (string, string, gin.HandlerFunc) {
+	return http.MethodGet, "/operator/trash", func(gc *gin.Context) {
+		resp, err := srv.GetTrashedOperators(gc.GetString(UserIdKey), isAdmin(gc), gc.Request.URL.Query())
+		if err != nil {
+			util.Logger.Error("error getting deleted operators", "error", err)
+			_ = gc.Error(err)
+			return
+		}
+		gc.JSON(http.StatusOK, resp)
+	}
+}
+
+// postRestoreOperator godoc
+// @Summary Restore operator
+// @Description	Takes an operator out of the trash and restores its permissions. The owner, the user who deleted it and admins may restore an operator.
+// @Tags Operator
+// @Produce json
+// @Param id path string true "Operator ID"
+// @Success	200 {object} lib.Operator
+// @Failure	400,403,404,412,500,503 {object} lib.ProblemDetails
+// @Router /operator/{id}/restore [post]
+func postRestoreOperator(srv service.Service) (string, string, gin.HandlerFunc) {
+	return http.MethodPost, "/operator/:id/restore", func(gc *gin.Context) {
+		resp, err := srv.WithRequestId(requestid.Get(gc)).RestoreOperator(gc.Param("id"), gc.GetString(UserIdKey), isAdmin(gc))
+		if err != nil {
+			util.Logger.Error("error restoring operator", "error", err)
+			_ = gc.Error(err)
+			return
+		}
+		gc.JSON(http.StatusOK, resp)
+	}
+}
+
+// postPublishOperator godoc
+// @Summary Publish operator
+// @Description	Publishes the current version of an operator, published versions can not be changed anymore. With If-Match, only the given revision is published.
+// @Tags Operator
+// @Param id path string true "Operator ID"
+// @Param If-Match header string false "ETag of the revision to publish, required if configured"
+// @Success	200
+// @Failure	400,403,404,409,412,428,500,503 {object} lib.ProblemDetails
+// @Router /operator/{id}/publish [post]
+func postPublishOperator(srv service.Service) (string, string, gin.HandlerFunc) {
+	return http.MethodPost, "/operator/:id/publish", func(gc *gin.Context) {
+		revision, err := parseIfMatch(gc.GetHeader(HeaderIfMatch))
+		if err != nil {
+			util.Logger.Error("error publishing operator", "error", err)
+			_ = gc.Error(err)
+			return
+		}
+		err = srv.WithRequestId(requestid.Get(gc)).PublishOperator(gc.Param("id"), gc.GetString(UserIdKey), revision, gc.GetHeader("Authorization"))
+		if err != nil {
+			util.Logger.Error("error publishing operator", "error", err)
+			_ = gc.Error(err)
+			return
+		}
+		gc.Status(http.StatusOK)
+	}
+}
+
+// getOperatorVersions godoc
+// @Summary Get operator versions
+// @Description	Gets all versions of an operator
+// @Tags Operator
+// @Produce json
+// @Param id path string true "Operator ID"
+// @Success	200 {object} lib.OperatorVersionsResponse
+// @Failure	400,403,404,500,503 {object} lib.ProblemDetails
+// @Router /operator/{id}/versions [get]
+func getOperatorVersions(srv service.Service) (string, string, gin.HandlerFunc) {
+	return http.MethodGet, "/operator/:id/versions", func(gc *gin.Context) {
+		resp, err := srv.GetOperatorVersions(gc.Param("id"), gc.GetString(UserIdKey), gc.GetHeader("Authorization"))
+		if err != nil {
+			util.Logger.Error("error getting operator versions", "error", err)
+			_ = gc.Error(err)
+			return
+		}
+		gc.JSON(http.StatusOK, resp)
+	}
+}
+
+// getOperatorVersion godoc
+// @Summary Get operator version
+// @Description	Gets a single version of an operator
+// @Tags Operator
+// @Produce json
+// @Param id path string true "Operator ID"
+// @Param version path string true "Semantic version"
+// @Success	200 {object} lib.OperatorVersion
+// @Failure	400,403,404,500,503 {object} lib.ProblemDetails
+// @Router /operator/{id}/versions/{version} [get]
+func getOperatorVersion(srv service.Service) (string, string, gin.HandlerFunc) {
+	return http.MethodGet, "/operator/:id/versions/:version", func(gc *gin.Context) {
+		resp, err := srv.GetOperatorVersion(gc.Param("id"), gc.Param("version"), gc.GetString(UserIdKey), gc.GetHeader("Authorization"))
+		if err != nil {
+			util.Logger.Error("error getting operator version", "error", err)
+			_ = gc.Error(err)
+			return
+		}
+		gc.JSON(http.StatusOK, resp)
+	}
+}
+
+// postValidateOperatorConfig godoc
+// @Summary Validate operator config
+// @Description	Validates a configuration against the config values of an operator and reports all problems per field
+// @Tags Operator
+// @Accept json
+// @Produce json
+// @Param id path string true "Operator ID"
+// @Param config body map[string]any true "Configuration"
+// @Success	200 {object} lib.ConfigValidationResponse
+// @Failure	400,403,404,500,503 {object} lib.ProblemDetails
+// @Router /operator/{id}/config/validate [post]
+func postValidateOperatorConfig(srv service.Service) (string, string, gin.HandlerFunc) {
+	return http.MethodPost, "/operator/:id/config/validate", func(gc *gin.Context) {
+		var request map[string]any
+		if err := gc.ShouldBindJSON(&request); err != nil {
+			util.Logger.Error("error validating operator config", "error", err)
+			_ = gc.Error(lib.NewInvalidInputError(err))
+			return
+		}
+		resp, err := srv.ValidateOperatorConfig(gc.Param("id"), request, gc.GetString(UserIdKey), gc.GetHeader("Authorization"))
+		if err != nil {
+			util.Logger.Error("error validating operator config", "error", err)
+			_ = gc.Error(err)
+			return
+		}
+		gc.JSON(http.StatusOK, resp)
+	}
+}
+
+// getOperatorPermissions godoc
+// @Summary Get operator permissions
+// @Description	Gets the user, group and role permissions of an operator, requires administrate rights
+// @Tags Operator
+// @Produce json
+// @Param id path string true "Operator ID"
+// @Success	200 {object} lib.OperatorPermissions
+// @Failure	400,403,404,500,503 {object} lib.ProblemDetails
+// @Router /operator/{id}/permissions [get]
+func getOperatorPermissions(srv service.Service) (string, string, gin.HandlerFunc) {
+	return http.MethodGet, "/operator/:id/permissions", func(gc *gin.Context) {
+		resp, err := srv.GetOperatorPermissions(gc.Param("id"), gc.GetString(UserIdKey), gc.GetHeader("Authorization"))
+		if err != nil {
+			util.Logger.Error("error getting operator permissions", "error", err)
+			_ = gc.Error(err)
+			return
+		}
+		gc.JSON(http.StatusOK, resp)
+	}
+}
+
+// putOperatorPermissions godoc
+// @Summary Set operator permissions
+// @Description	Replaces the user, group and role permissions of an operator, requires administrate rights. The owner keeps all rights.
+// @Tags Operator
+// @Accept json
+// @Produce json
+// @Param id path string true "Operator ID"
+// @Param permissions body lib.OperatorPermissions true "Permissions"
+// @Success	200 {object} lib.OperatorPermissions
+// @Failure	400,403,404,500,503 {object} lib.ProblemDetails
+// @Router /operator/{id}/permissions [put]
+func putOperatorPermissions(srv service.Service) (string, string, gin.HandlerFunc) {
+	return http.MethodPut, "/operator/:id/permissions", func(gc *gin.Context) {
+		var request lib.OperatorPermissions
+		if err := gc.ShouldBindJSON(&request); err != nil {
+			util.Logger.Error("error setting operator permissions", "error", err)
+			_ = gc.Error(lib.NewInvalidInputError(err))
+			return
+		}
+		resp, err := srv.WithRequestId(requestid.Get(gc)).SetOperatorPermissions(gc.Param("id"), request, gc.GetString(UserIdKey), gc.GetHeader("Authorization"))
+		if err != nil {
+			util.Logger.Error("error setting operator permissions", "error", err)
+			_ = gc.Error(err)
+			return
+		}
+		gc.JSON(http.StatusOK, resp)
+	}
+}
+
+// postTransferOperator godoc
+// @Summary Transfer operator
+// @Description	Transfers the ownership of an operator to another user, only the owner may do so
+// @Tags Operator
+// @Accept json
+// @Param id path string true "Operator ID"
+// @Param request body lib.TransferRequest true "New owner"
+// @Success	204
+// @Failure	400,403,404,500,503 {object} lib.ProblemDetails
+// @Router /operator/{id}/transfer [post]
+func postTransferOperator(srv service.Service) (string, string, gin.HandlerFunc) {
+	return http.MethodPost, "/operator/:id/transfer", func(gc *gin.Context) {
+		var request lib.TransferRequest
+		if err := gc.ShouldBindJSON(&request); err != nil {
+			util.Logger.Error("error transferring operator", "error", err)
+			_ = gc.Error(lib.NewInvalidInputError(err))
+			return
+		}
+		err := srv.WithRequestId(requestid.Get(gc)).TransferOperator(gc.Param("id"), request.UserId, gc.GetString(UserIdKey), gc.GetHeader("Authorization"))
+		if err != nil {
+			util.Logger.Error("error transferring operator", "error", err)
+			_ = gc.Error(err)
 			return
 		}
 		gc.Status(http.StatusNoContent)
 	}
 }
 
+// postReassignOperators godoc
+// @Summary Reassign operators
+// @Description	Transfers all operators of a user to another user, requires the admin role
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param request body lib.ReassignRequest true "Previous and new owner"
+// @Success	200 {object} lib.ReassignResponse
+// @Failure	400,403,500,503 {object} lib.ProblemDetails
+// @Router /admin/operator/reassign [post]
+func postReassignOperators(srv service.Service) (string, string, gin.HandlerFunc) {
+	return http.MethodPost, "/admin/operator/reassign", func(gc *gin.Context) {
+		if !isAdmin(gc) {
+			_ = gc.Error(lib.NewForbiddenError(errors.New(MessageAdminRequired)))
+			return
+		}
+		var request lib.ReassignRequest
+		if err := gc.ShouldBindJSON(&request); err != nil {
+			util.Logger.Error("error reassigning operators", "error", err)
+			_ = gc.Error(lib.NewInvalidInputError(err))
+			return
+		}
+		resp, err := srv.WithRequestId(requestid.Get(gc)).ReassignOperators(request.FromUserId, request.ToUserId, gc.GetString(UserIdKey))
+		if err != nil {
+			util.Logger.Error("error reassigning operators", "error", err)
+			_ = gc.Error(err)
+			return
+		}
+		gc.JSON(http.StatusOK, resp)
+	}
+}
+
+// getOperatorHistory godoc
+// @Summary Get operator history
+// @Description	Gets the recorded changes of an operator with actor, time, request ID and changed fields, latest first
+// @Tags Operator
+// @Produce json
+// @Param id path string true "Operator ID"
+// @Param action query string false "Only changes of this action: created, updated, trashed, restored, purged or permissions_changed, deleted for changes recorded before the trash"
+// @Param actor query string false "Only changes made by this user"
+// @Param from query string false "Only changes at or after this RFC 3339 timestamp"
+// @Param to query string false "Only changes before this RFC 3339 timestamp"
+// @Param limit query int false "Limit, defaults to 100"
+// @Param offset query int false "Offset"
+// @Success	200 {object} lib.HistoryResponse
+// @Failure	400,403,404,500,503 {object} lib.ProblemDetails
+// @Router /operator/{id}/history [get]
+func getOperatorHistory(srv service.Service) (string, string, gin.HandlerFunc) {
+	return http.MethodGet, "/operator/:id/history", func(gc *gin.Context) {
+		resp, err := srv.GetOperatorHistory(gc.Param("id"), gc.GetString(UserIdKey), gc.Request.URL.Query(), gc.GetHeader("Authorization"))
+		if err != nil {
+			util.Logger.Error("error getting operator history", "error", err)
+			_ = gc.Error(err)
+			return
+		}
+		gc.JSON(http.StatusOK, resp)
+	}
+}
+
+// getAuditHistory godoc
+// @Summary Get audit trail
+// @Description	Gets the recorded changes of all operators, including deleted ones, latest first. Requires the admin role.
+// @Tags Admin
+// @Produce json
+// @Param operatorId query string false "Only changes of this operator"
+// @Param action query string false "Only changes of this action: created, updated, trashed, restored, purged or permissions_changed, deleted for changes recorded before the trash"
+// @Param actor query string false "Only changes made by this user"
+// @Param requestId query string false "Only changes made by this request"
+// @Param from query string false "Only changes at or after this RFC 3339 timestamp"
+// @Param to query string false "Only changes before this RFC 3339 timestamp"
+// @Param limit query int false "Limit, defaults to 100"
+// @Param offset query int false "Offset"
+// @Success	200 {object} lib.HistoryResponse
+// @Failure	400,403,500,503 {object} lib.ProblemDetails
+// @Router /admin/operator/history [get]
+func getAuditHistory(srv service.Service) (string, string, gin.HandlerFunc) {
+	return http.MethodGet, "/admin/operator/history", func(gc *gin.Context) {
+		if !isAdmin(gc) {
+			_ = gc.Error(lib.NewForbiddenError(errors.New(MessageAdminRequired)))
+			return
+		}
+		resp, err := srv.GetAuditHistory(gc.Request.URL.Query())
+		if err != nil {
+			util.Logger.Error("error getting audit history", "error", err)
+			_ = gc.Error(err)
+			return
+		}
+		gc.JSON(http.StatusOK, resp)
+	}
+}
+
+// getOperatorEvents godoc
+// @Summary Stream operator events
+// @Description	Streams created, updated, trashed, restored and purged events of all readable operators as server-sent events. Each message carries the CloudEvent as data, the Last-Event-ID header resumes a stream.
+// @Tags Operator
+// @Produce text/event-stream
+// @Param Last-Event-ID header string false "ID of the last received event"
+// @Success	200 {object} lib.OperatorEvent
+// @Failure	400,500 {object} lib.ProblemDetails
+// @Router /operator/events [get]
+func getOperatorEvents(srv service.Service) (string, string, gin.HandlerFunc) {
+	return http.MethodGet, "/operator/events", func(gc *gin.Context) {
+		events, err := srv.StreamOperatorEvents(gc.Request.Context(), gc.GetHeader(HeaderLastEventID), gc.GetString(UserIdKey), gc.GetHeader("Authorization"))
+		if err != nil {
+			util.Logger.Error("error streaming operator events", "error", err)
+			_ = gc.Error(err)
+			return
+		}
+		gc.Header("Cache-Control", "no-cache")
+		gc.Header("X-Accel-Buffering", "no")
+		keepAlive := time.NewTicker(StreamKeepAliveInterval)
+		defer keepAlive.Stop()
+		gc.Stream(func(w io.Writer) bool {
+			select {
+			case event, ok := <-events:
+				if !ok {
+					return false
+				}
+				gc.Render(-1, sse.Event{
+					Id:    event.Id,
+					Event: strings.TrimPrefix(event.Type, lib.EventTypePrefix),
+					Data:  event,
+				})
+			case <-keepAlive.C:
+				_, _ = io.WriteString(w, ": keep-alive\n\n")
+			}
+			return true
+		})
+	}
+}
+
 func getHealthCheckH(_ service.Service) (string, string, gin.HandlerFunc) {
 	return http.MethodGet, HealthCheckPath, func(c *gin.Context) {
 		c.Status(http.StatusOK)
@@ -23,9 +23,25 @@ import (
 
 var routesAuth = gin_mw.Routes[service.Service]{
 	getAll,
+	getOperatorEvents,
 	getOperator,
+	postBatchGetOperators,
 	postOperator,
+	patchOperator,
+	postBulkUpdateOperators,
 	putOperator,
 	deleteOperator,
 	deleteOperators,
+	getTrashedOperators,
+	postRestoreOperator,
+	postPublishOperator,
+	getOperatorVersions,
+	getOperatorVersion,
+	postValidateOperatorConfig,
+	getOperatorPermissions,
+	putOperatorPermissions,
+	postTransferOperator,
+	postReassignOperators,
+	getOperatorHistory,
+	getAuditHistory,
 }
@@ -23,15 +23,49 @@ import (
 )
 
 type Config struct {
-	Debug            bool          `json:"debug" env_var:"DEBUG"`
-	ServerPort       int           `json:"server_port" env_var:"SERVER_PORT"`
-	Logger           LoggerConfig  `json:"logger" env_var:"LOGGER_CONFIG"`
-	MongoUrl         string        `json:"mongo_url" env_var:"MONGO_URL"`
-	HttpTimeout      time.Duration `json:"http_timeout" env_var:"HTTP_TIMEOUT"`
-	PermissionsV2Url string        `json:"permissions_v2_url" env_var:"PERMISSIONS_V2_URL"`
-	URLPrefix        string        `json:"url_prefix" env_var:"URL_PREFIX"`
+	Debug               bool                 `json:"debug" env_var:"DEBUG"`
+	ServerPort          int                  `json:"server_port" env_var:"SERVER_PORT"`
+	Logger              LoggerConfig         `json:"logger" env_var:"LOGGER_CONFIG"`
+	MongoUrl            string               `json:"mongo_url" env_var:"MONGO_URL"`
+	HttpTimeout         time.Duration        `json:"http_timeout" env_var:"HTTP_TIMEOUT"`
+	PermissionsV2Url    string               `json:"permissions_v2_url" env_var:"PERMISSIONS_V2_URL"`
+	URLPrefix           string               `json:"url_prefix" env_var:"URL_PREFIX"`
+	KafkaBootstrap      string               `json:"kafka_bootstrap" env_var:"KAFKA_BOOTSTRAP"`
+	IdempotencyTTL      time.Duration        `json:"idempotency_ttl" env_var:"IDEMPOTENCY_TTL"`
+	ReadersSyncInterval time.Duration        `json:"readers_sync_interval" env_var:"READERS_SYNC_INTERVAL"`
+	RequireIfMatch      bool                 `json:"require_if_match" env_var:"REQUIRE_IF_MATCH"`
+	TrashRetention      time.Duration        `json:"trash_retention" env_var:"TRASH_RETENTION"`
+	TrashPurgeInterval  time.Duration        `json:"trash_purge_interval" env_var:"TRASH_PURGE_INTERVAL"`
+	UserEvents          UserEventsConfig     `json:"user_events" env_var:"USER_EVENTS_CONFIG"`
+	OperatorEvents      OperatorEventsConfig `json:"operator_events" env_var:"OPERATOR_EVENTS_CONFIG"`
 }
 
+// OperatorEventsConfig controls the publishing of operator lifecycle events as CloudEvents.
+type OperatorEventsConfig struct {
+	Enabled       bool          `json:"enabled" env_var:"OPERATOR_EVENTS_ENABLED"`
+	Topic         string        `json:"topic" env_var:"OPERATOR_EVENTS_TOPIC"`
+	RetryInterval time.Duration `json:"retry_interval" env_var:"OPERATOR_EVENTS_RETRY_INTERVAL"`
+	// RecoveryInterval is the interval of searching for operators whose events have been lost.
+	RecoveryInterval time.Duration `json:"recovery_interval" env_var:"OPERATOR_EVENTS_RECOVERY_INTERVAL"`
+}
+
+// UserEventsConfig controls how operators of deleted users are handled.
+// Private operators are always deleted, public and shared operators are handled by their policy.
+type UserEventsConfig struct {
+	Enabled      bool   `json:"enabled" env_var:"USER_EVENTS_ENABLED"`
+	Topic        string `json:"topic" env_var:"USER_EVENTS_TOPIC"`
+	GroupId      string `json:"group_id" env_var:"USER_EVENTS_GROUP_ID"`
+	PublicPolicy string `json:"public_policy" env_var:"USER_EVENTS_PUBLIC_POLICY"`
+	SharedPolicy string `json:"shared_policy" env_var:"USER_EVENTS_SHARED_POLICY"`
+	ReassignTo   string `json:"reassign_to" env_var:"USER_EVENTS_REASSIGN_TO"`
+}
+
+const (
+	UserDeletePolicyDelete   = "delete"
+	UserDeletePolicyKeep     = "keep"
+	UserDeletePolicyReassign = "reassign"
+)
+
 type LoggerConfig struct {
 	Level string `json:"level" env_var:"LOGGER_LEVEL"`
 }
@@ -45,6 +79,24 @@ func New(path string) (*Config, error) {
 		HttpTimeout:      30 * time.Second,
 		PermissionsV2Url: "http://permv2.permissions:8080",
 		URLPrefix:        "",
+		KafkaBootstrap:   "localhost:9092",
+		UserEvents: UserEventsConfig{
+			Enabled:      false,
+			Topic:        "user",
+			GroupId:      "analytics-operator-repo-v2",
+			PublicPolicy: UserDeletePolicyKeep,
+			SharedPolicy: UserDeletePolicyKeep,
+		},
+		IdempotencyTTL:      24 * time.Hour,
+		ReadersSyncInterval: 15 * time.Minute,
+		TrashRetention:      30 * 24 * time.Hour,
+		TrashPurgeInterval:  time.Hour,
+		OperatorEvents: OperatorEventsConfig{
+			Enabled:          false,
+			Topic:            "analytics-operator-events",
+			RetryInterval:    10 * time.Second,
+			RecoveryInterval: time.Minute,
+		},
 	}
 	err := sb_config_hdl.Load(&cfg, nil, envTypeParser, nil, path)
 	return &cfg, err
@@ -18,6 +18,18 @@ package db
 
 const PermV2InstanceTopic = "analytics-operators"
 
+// PublicRole is granted read and execute rights on public operators.
+const PublicRole = "user"
+
+const AdminRole = "admin"
+
 const (
-	MessageMissingRights = "requested instance nonexistent or missing rights"
+	MessageMissingRights    = "requested instance nonexistent or missing rights"
+	MessageNotFound         = "requested instance nonexistent"
+	MessageInvalidId        = "invalid id"
+	MessageBatchAborted     = "not deleted, the batch was aborted"
+	MessageRevisionMismatch = "operator was changed in the meantime, revision does not match"
 )
+
+// MaxBatchSize limits the number of operators of a single batch request.
+const MaxBatchSize = 1000
@@ -0,0 +1,123 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package db
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/SENERGY-Platform/analytics-operator-repo-v2/lib"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+var errInvalidCursor = errors.New("invalid cursor")
+
+// pageCursor marks the position after the last operator of a page.
+// It holds the values of all sort keys, the id is always the last sort key and breaks ties.
+// Values are kept as raw bson, so types like dates survive the round trip.
+type pageCursor struct {
+	Sort   string          `bson:"s"`
+	Values []bson.RawValue `bson:"v"`
+}
+
+// sortSpec is the canonical form of a sort, a cursor is only valid for the sort it was created with.
+func sortSpec(sort bson.D) string {
+	parts := make([]string, 0, len(sort))
+	for _, key := range sort {
+		parts = append(parts, fmt.Sprintf("%s:%v", key.Key, key.Value))
+	}
+	return strings.Join(parts, ",")
+}
+
+func encodeCursor(sort bson.D, operator lib.Operator) (string, error) {
+	raw, err := bson.Marshal(operator)
+	if err != nil {
+		return "", err
+	}
+	cursor := pageCursor{Sort: sortSpec(sort)}
+	for _, key := range sort {
+		value, err := bson.Raw(raw).LookupErr(strings.Split(key.Key, ".")...)
+		if err != nil {
+			value = bson.RawValue{Type: bson.TypeNull}
+		}
+		cursor.Values = append(cursor.Values, value)
+	}
+	b, err := bson.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func decodeCursor(sort bson.D, s string) (cursor pageCursor, err error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return cursor, lib.NewInvalidInputError(errInvalidCursor)
+	}
+	if err = bson.Unmarshal(b, &cursor); err != nil || len(cursor.Values) != len(sort) {
+		return cursor, lib.NewInvalidInputError(errInvalidCursor)
+	}
+	if cursor.Sort != sortSpec(sort) {
+		return cursor, lib.NewInvalidInputError(errors.New("cursor was created for a different sort"))
+	}
+	return
+}
+
+// seekFilter matches all operators after the cursor position in the given sort order.
+// Missing values sort before all others, like mongo does it.
+func seekFilter(sort bson.D, cursor pageCursor) bson.M {
+	var branches bson.A
+	equal := bson.M{}
+	for i, key := range sort {
+		value := cursor.Values[i]
+		isNull := value.Type == bson.TypeNull || value.Type == bson.TypeUndefined
+		desc := key.Value == -1
+		var after bson.M
+		switch {
+		case isNull && !desc:
+			after = bson.M{key.Key: bson.M{"$ne": nil}}
+		case isNull && desc:
+			after = nil
+		case desc:
+			after = bson.M{"$or": bson.A{bson.M{key.Key: bson.M{"$lt": value}}, bson.M{key.Key: nil}}}
+		default:
+			after = bson.M{key.Key: bson.M{"$gt": value}}
+		}
+		if after != nil {
+			branch := bson.M{}
+			for k, v := range equal {
+				branch[k] = v
+			}
+			if len(branch) == 0 {
+				branches = append(branches, after)
+			} else {
+				branches = append(branches, bson.M{"$and": bson.A{branch, after}})
+			}
+		}
+		if isNull {
+			equal[key.Key] = nil
+		} else {
+			equal[key.Key] = value
+		}
+	}
+	if len(branches) == 0 {
+		return bson.M{"_id": nil}
+	}
+	return bson.M{"$or": branches}
+}
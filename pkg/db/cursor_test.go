@@ -0,0 +1,157 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package db
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/SENERGY-Platform/analytics-operator-repo-v2/lib"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func rawValue(t *testing.T, v any) bson.RawValue {
+	t.Helper()
+	if v == nil {
+		return bson.RawValue{Type: bson.TypeNull}
+	}
+	typ, data, err := bson.MarshalValue(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bson.RawValue{Type: typ, Value: data}
+}
+
+func TestSeekFilter(t *testing.T) {
+	id := bson.NewObjectID()
+	cases := []struct {
+		name     string
+		sort     bson.D
+		values   []any
+		expected func(values []bson.RawValue) bson.M
+	}{
+		{
+			name:   "asc",
+			sort:   bson.D{{Key: "name", Value: 1}, {Key: "_id", Value: 1}},
+			values: []any{"b", id},
+			expected: func(v []bson.RawValue) bson.M {
+				return bson.M{"$or": bson.A{
+					bson.M{"name": bson.M{"$gt": v[0]}},
+					bson.M{"$and": bson.A{bson.M{"name": v[0]}, bson.M{"_id": bson.M{"$gt": v[1]}}}},
+				}}
+			},
+		},
+		{
+			name:   "desc includes missing values",
+			sort:   bson.D{{Key: "name", Value: -1}, {Key: "_id", Value: 1}},
+			values: []any{"b", id},
+			expected: func(v []bson.RawValue) bson.M {
+				return bson.M{"$or": bson.A{
+					bson.M{"$or": bson.A{bson.M{"name": bson.M{"$lt": v[0]}}, bson.M{"name": nil}}},
+					bson.M{"$and": bson.A{bson.M{"name": v[0]}, bson.M{"_id": bson.M{"$gt": v[1]}}}},
+				}}
+			},
+		},
+		{
+			name:   "null asc is followed by all values",
+			sort:   bson.D{{Key: "name", Value: 1}, {Key: "_id", Value: 1}},
+			values: []any{nil, id},
+			expected: func(v []bson.RawValue) bson.M {
+				return bson.M{"$or": bson.A{
+					bson.M{"name": bson.M{"$ne": nil}},
+					bson.M{"$and": bson.A{bson.M{"name": nil}, bson.M{"_id": bson.M{"$gt": v[1]}}}},
+				}}
+			},
+		},
+		{
+			name:   "null desc is followed by nulls only",
+			sort:   bson.D{{Key: "name", Value: -1}, {Key: "_id", Value: 1}},
+			values: []any{nil, id},
+			expected: func(v []bson.RawValue) bson.M {
+				return bson.M{"$or": bson.A{
+					bson.M{"$and": bson.A{bson.M{"name": nil}, bson.M{"_id": bson.M{"$gt": v[1]}}}},
+				}}
+			},
+		},
+		{
+			name:   "null desc without tie breaker matches nothing",
+			sort:   bson.D{{Key: "name", Value: -1}},
+			values: []any{nil},
+			expected: func(v []bson.RawValue) bson.M {
+				return bson.M{"_id": nil}
+			},
+		},
+		{
+			name:   "mixed directions",
+			sort:   bson.D{{Key: "pub", Value: -1}, {Key: "cost", Value: 1}, {Key: "_id", Value: 1}},
+			values: []any{true, 1.5, id},
+			expected: func(v []bson.RawValue) bson.M {
+				return bson.M{"$or": bson.A{
+					bson.M{"$or": bson.A{bson.M{"pub": bson.M{"$lt": v[0]}}, bson.M{"pub": nil}}},
+					bson.M{"$and": bson.A{bson.M{"pub": v[0]}, bson.M{"cost": bson.M{"$gt": v[1]}}}},
+					bson.M{"$and": bson.A{bson.M{"pub": v[0], "cost": v[1]}, bson.M{"_id": bson.M{"$gt": v[2]}}}},
+				}}
+			},
+		},
+		{
+			name:   "null in the middle",
+			sort:   bson.D{{Key: "pub", Value: -1}, {Key: "cost", Value: -1}, {Key: "_id", Value: 1}},
+			values: []any{true, nil, id},
+			expected: func(v []bson.RawValue) bson.M {
+				return bson.M{"$or": bson.A{
+					bson.M{"$or": bson.A{bson.M{"pub": bson.M{"$lt": v[0]}}, bson.M{"pub": nil}}},
+					bson.M{"$and": bson.A{bson.M{"pub": v[0], "cost": nil}, bson.M{"_id": bson.M{"$gt": v[2]}}}},
+				}}
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cursor := pageCursor{Sort: sortSpec(c.sort)}
+			for _, value := range c.values {
+				cursor.Values = append(cursor.Values, rawValue(t, value))
+			}
+			filter := seekFilter(c.sort, cursor)
+			if expected := c.expected(cursor.Values); !reflect.DeepEqual(filter, expected) {
+				t.Errorf("expected %v, got %v", expected, filter)
+			}
+		})
+	}
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	id := bson.NewObjectID()
+	sort := bson.D{{Key: "name", Value: 1}, {Key: "cost", Value: -1}, {Key: "_id", Value: 1}}
+	s, err := encodeCursor(sort, lib.Operator{Id: &id, Name: "op"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cursor, err := decodeCursor(sort, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []bson.RawValue{rawValue(t, "op"), rawValue(t, nil), rawValue(t, id)}
+	if !reflect.DeepEqual(cursor.Values, expected) {
+		t.Errorf("expected %v, got %v", expected, cursor.Values)
+	}
+	if _, err = decodeCursor(bson.D{{Key: "name", Value: -1}, {Key: "cost", Value: -1}, {Key: "_id", Value: 1}}, s); err == nil {
+		t.Error("expected error for cursor of a different sort")
+	}
+	if _, err = decodeCursor(sort, "not a cursor"); err == nil {
+		t.Error("expected error for invalid cursor")
+	}
+}
@@ -53,6 +53,23 @@ func (db *MongoDB) OperatorCollection() *mongo.Collection {
 	return db.client.Database("db").Collection("operators")
 }
 
+func (db *MongoDB) OperatorVersionCollection() *mongo.Collection {
+	return db.client.Database("db").Collection("operator_versions")
+}
+
+func (db *MongoDB) OutboxCollection() *mongo.Collection {
+	return db.client.Database("db").Collection("operator_outbox")
+}
+
+func (db *MongoDB) IdempotencyCollection() *mongo.Collection {
+	return db.client.Database("db").Collection("operator_idempotency")
+}
+
+// HistoryCollection decodes nested documents as maps, so the old and new values of changes can be returned as JSON objects.
+func (db *MongoDB) HistoryCollection() *mongo.Collection {
+	return db.client.Database("db").Collection("operator_history", options.Collection().SetBSONOptions(&options.BSONOptions{DefaultDocumentM: true}))
+}
+
 func SetDefaultPermissions(instance lib.Operator, permissions permV2Client.ResourcePermissions) {
 	permissions.UserPermissions[instance.UserId] = permV2Client.PermissionsMap{
 		Read:         true,
@@ -60,6 +77,44 @@ func SetDefaultPermissions(instance lib.Operator, permissions permV2Client.Resou
 		Execute:      true,
 		Administrate: true,
 	}
+	if instance.Pub {
+		permissions.RolePermissions[PublicRole] = permV2Client.PermissionsMap{
+			Read:    true,
+			Execute: true,
+		}
+	} else {
+		delete(permissions.RolePermissions, PublicRole)
+	}
+}
+
+func toOperatorPermissions(permissions permV2Client.ResourcePermissions) lib.OperatorPermissions {
+	convert := func(m map[string]permV2Client.PermissionsMap) map[string]lib.PermissionsMap {
+		result := make(map[string]lib.PermissionsMap, len(m))
+		for key, value := range m {
+			result[key] = lib.PermissionsMap(value)
+		}
+		return result
+	}
+	return lib.OperatorPermissions{
+		UserPermissions:  convert(permissions.UserPermissions),
+		GroupPermissions: convert(permissions.GroupPermissions),
+		RolePermissions:  convert(permissions.RolePermissions),
+	}
+}
+
+func fromOperatorPermissions(permissions lib.OperatorPermissions) permV2Client.ResourcePermissions {
+	convert := func(m map[string]lib.PermissionsMap) map[string]permV2Client.PermissionsMap {
+		result := make(map[string]permV2Client.PermissionsMap, len(m))
+		for key, value := range m {
+			result[key] = permV2Client.PermissionsMap(value)
+		}
+		return result
+	}
+	return permV2Client.ResourcePermissions{
+		UserPermissions:  convert(permissions.UserPermissions),
+		GroupPermissions: convert(permissions.GroupPermissions),
+		RolePermissions:  convert(permissions.RolePermissions),
+	}
 }
 
 func getTimeoutContext(basectx context.Context) (context.Context, context.CancelFunc) {
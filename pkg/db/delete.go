@@ -0,0 +1,164 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/SENERGY-Platform/analytics-operator-repo-v2/lib"
+	"github.com/SENERGY-Platform/analytics-operator-repo-v2/pkg/util"
+	permV2Client "github.com/SENERGY-Platform/permissions-v2/pkg/client"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// deletion holds the state of an operator before it was moved to the trash, so the deletion can be undone.
+type deletion struct {
+	id          string
+	operator    bson.Raw
+	permissions *permV2Client.ResourcePermissions
+	removedPerm bool
+}
+
+// DeleteOperators moves all operators of the ID list to the trash or none of them.
+// If a single operator can not be deleted, the batch is rejected and every operator is reported with an HTTP status code.
+// Permissions-v2 does not take part in Mongo transactions, so a failed deletion is rolled back by restoring what has been changed before.
+// On a dry run the operators are checked only.
+func (r *MongoRepo) DeleteOperators(ids []string, userId string, admin bool, dryRun bool, auth string) (response lib.BatchDeleteResponse, err error) {
+	ids = uniqueIds(ids)
+	if len(ids) > MaxBatchSize {
+		return response, lib.NewInvalidInputError(fmt.Errorf("at most %d ids are allowed", MaxBatchSize))
+	}
+	if admin {
+		auth = permV2Client.InternalAdminToken
+	}
+	response.DryRun = dryRun
+	response.Deleted = []string{}
+	response.Results = make([]lib.ItemStatus, 0, len(ids))
+	var objIds []bson.ObjectID
+	for _, id := range ids {
+		if objId, e := parseObjectID(id); e == nil {
+			objIds = append(objIds, objId)
+		}
+	}
+	found := map[string]bool{}
+	var foundIds []string
+	if len(objIds) > 0 {
+		cur, err := r.coll.Find(context.TODO(), bson.M{"_id": bson.M{"$in": objIds}, "dateDeleted": bson.M{"$exists": false}}, options.Find().SetProjection(bson.M{"_id": 1}))
+		if err != nil {
+			return response, mongoError(err)
+		}
+		var operators []lib.Operator
+		if err = cur.All(context.TODO(), &operators); err != nil {
+			return response, mongoError(err)
+		}
+		for _, operator := range operators {
+			found[operator.Id.Hex()] = true
+			foundIds = append(foundIds, operator.Id.Hex())
+		}
+	}
+	access := map[string]bool{}
+	if len(foundIds) > 0 {
+		var code int
+		access, err, code = r.perm.CheckMultiplePermissions(auth, PermV2InstanceTopic, foundIds, permV2Client.Administrate)
+		if err != nil {
+			return response, permError(err, code)
+		}
+	}
+	rejected := false
+	for _, id := range ids {
+		status := lib.ItemStatus{Id: id, Status: http.StatusOK}
+		switch {
+		case !found[id]:
+			if _, e := parseObjectID(id); e != nil {
+				status = lib.ItemStatus{Id: id, Status: http.StatusBadRequest, Detail: MessageInvalidId}
+			} else {
+				status = lib.ItemStatus{Id: id, Status: http.StatusNotFound, Detail: MessageNotFound}
+			}
+		case !access[id]:
+			status = lib.ItemStatus{Id: id, Status: http.StatusForbidden, Detail: MessageMissingRights}
+		}
+		rejected = rejected || status.Status != http.StatusOK
+		response.Results = append(response.Results, status)
+	}
+	if rejected {
+		abortResults(response.Results)
+		return
+	}
+	if dryRun {
+		response.Deleted = ids
+		return
+	}
+	var done []*deletion
+	for i, id := range ids {
+		d := &deletion{id: id}
+		done = append(done, d)
+		if e := r.trashOperator(d, userId, nil); e != nil {
+			util.Logger.Error("error deleting operator, rolling back batch", "error", e, "id", id)
+			if rbErr := r.rollbackDeletions(done); rbErr != nil {
+				return response, errors.Join(e, rbErr)
+			}
+			response.Results[i] = itemError(id, e)
+			abortResults(response.Results)
+			return response, nil
+		}
+	}
+	response.Deleted = ids
+	return
+}
+
+// rollbackDeletions takes the operators out of the trash again and restores their permissions in reverse order.
+func (r *MongoRepo) rollbackDeletions(deletions []*deletion) error {
+	var errs []error
+	for i := len(deletions) - 1; i >= 0; i-- {
+		d := deletions[i]
+		if d.operator != nil {
+			if _, err := r.coll.ReplaceOne(context.TODO(), bson.M{"_id": d.operator.Lookup("_id")}, d.operator); err != nil {
+				errs = append(errs, fmt.Errorf("restoring operator %s: %w", d.id, err))
+			}
+		}
+		if d.removedPerm {
+			if _, err := r.setPermission(d.id, *d.permissions); err != nil {
+				errs = append(errs, fmt.Errorf("restoring permissions of operator %s: %w", d.id, err))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		util.Logger.Error("error rolling back deletion", "error", errors.Join(errs...))
+	}
+	return errors.Join(errs...)
+}
+
+// abortResults marks all operators that could have been deleted as not deleted.
+func abortResults(results []lib.ItemStatus) {
+	for i, result := range results {
+		if result.Status == http.StatusOK {
+			results[i] = lib.ItemStatus{Id: result.Id, Status: http.StatusFailedDependency, Detail: MessageBatchAborted}
+		}
+	}
+}
+
+func itemError(id string, err error) lib.ItemStatus {
+	status := lib.StatusCode(err)
+	if status == 0 {
+		return lib.ItemStatus{Id: id, Status: http.StatusInternalServerError, Detail: "error deleting operator"}
+	}
+	return lib.ItemStatus{Id: id, Status: status, Detail: err.Error()}
+}
@@ -0,0 +1,82 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package db
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/SENERGY-Platform/analytics-operator-repo-v2/lib"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/x/mongo/driver/topology"
+)
+
+// Mongo server error codes of expected failures.
+const (
+	mongoCodeNamespaceNotFound = 26
+	mongoCodeIndexNotFound     = 27
+)
+
+// mongoError wraps database errors into the matching lib error type.
+func mongoError(err error) error {
+	if err == nil {
+		return nil
+	}
+	switch {
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return lib.NewNotFoundError(errors.New(MessageNotFound))
+	case mongo.IsDuplicateKeyError(err):
+		return lib.NewConflictError(err)
+	case mongo.IsTimeout(err), mongo.IsNetworkError(err), errors.Is(err, mongo.ErrClientDisconnected), errors.As(err, &topology.ServerSelectionError{}):
+		return lib.NewServiceUnavailableError(err)
+	}
+	return err
+}
+
+// permError wraps permissions-v2 client errors into the matching lib error type.
+func permError(err error, code int) error {
+	if err == nil {
+		return nil
+	}
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return lib.NewServiceUnavailableError(err)
+	}
+	switch code {
+	case http.StatusBadRequest:
+		return lib.NewInvalidInputError(err)
+	case http.StatusUnauthorized:
+		return lib.NewUnauthorizedError(err)
+	case http.StatusForbidden:
+		return lib.NewForbiddenError(err)
+	case http.StatusNotFound:
+		return lib.NewNotFoundError(err)
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return lib.NewServiceUnavailableError(err)
+	}
+	return err
+}
+
+func parseObjectID(id string) (objId bson.ObjectID, err error) {
+	objId, err = bson.ObjectIDFromHex(id)
+	if err != nil {
+		return objId, lib.NewInvalidInputError(errors.New(MessageInvalidId + ": " + id))
+	}
+	return
+}
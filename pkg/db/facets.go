@@ -0,0 +1,115 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package db
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/SENERGY-Platform/analytics-operator-repo-v2/lib"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type facetField struct {
+	path   string
+	unwind string
+}
+
+// facetFields is the allowlist of fields that can be counted, array fields are unwound first.
+var facetFields = map[string]facetField{
+	"deploymentType":     {path: "deploymentType"},
+	"pub":                {path: "pub"},
+	"published":          {path: "published"},
+	"userId":             {path: "userId"},
+	"tags":               {path: "tags", unwind: "tags"},
+	"inputs.name":        {path: "inputs.name", unwind: "inputs"},
+	"inputs.type":        {path: "inputs.type", unwind: "inputs"},
+	"outputs.name":       {path: "outputs.name", unwind: "outputs"},
+	"outputs.type":       {path: "outputs.type", unwind: "outputs"},
+	"config_values.name": {path: "config_values.name", unwind: "config_values"},
+}
+
+func parseFacets(value string) (fields []string, err error) {
+	for _, field := range strings.Split(value, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" || slices.Contains(fields, field) {
+			continue
+		}
+		if _, ok := facetFields[field]; !ok {
+			names := slices.Sorted(maps.Keys(facetFields))
+			return nil, lib.NewInvalidInputError(errors.New("unknown facet " + strconv.Quote(field) + ", allowed are " + strings.Join(names, ", ")))
+		}
+		fields = append(fields, field)
+	}
+	return
+}
+
+// facetPipeline counts the operators per value of the field, operators with repeated values in arrays are counted once.
+func facetPipeline(field facetField) bson.A {
+	if field.unwind == "" {
+		return bson.A{
+			bson.M{"$group": bson.M{"_id": "$" + field.path, "count": bson.M{"$sum": 1}}},
+			bson.M{"$sort": bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}},
+		}
+	}
+	return bson.A{
+		bson.M{"$unwind": "$" + field.unwind},
+		bson.M{"$group": bson.M{"_id": bson.M{"operator": "$_id", "value": "$" + field.path}}},
+		bson.M{"$group": bson.M{"_id": "$_id.value", "count": bson.M{"$sum": 1}}},
+		bson.M{"$sort": bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}},
+	}
+}
+
+// facets counts values of the given fields over all operators matching the query.
+func (r *MongoRepo) facets(q listQuery, fields []string) (facets map[string][]lib.FacetCount, err error) {
+	pipelines := bson.M{}
+	for i, field := range fields {
+		pipelines["f"+strconv.Itoa(i)] = facetPipeline(facetFields[field])
+	}
+	cur, err := r.coll.Aggregate(context.TODO(), mongo.Pipeline{
+		{{Key: "$match", Value: q.filter}},
+		{{Key: "$facet", Value: pipelines}},
+	}, options.Aggregate().SetCollation(q.collation))
+	if err != nil {
+		return nil, mongoError(err)
+	}
+	var results []map[string][]struct {
+		Value any   `bson:"_id"`
+		Count int64 `bson:"count"`
+	}
+	err = cur.All(context.TODO(), &results)
+	if err != nil {
+		return nil, mongoError(err)
+	}
+	facets = make(map[string][]lib.FacetCount, len(fields))
+	for i, field := range fields {
+		facets[field] = make([]lib.FacetCount, 0)
+		if len(results) == 0 {
+			continue
+		}
+		for _, bucket := range results[0]["f"+strconv.Itoa(i)] {
+			facets[field] = append(facets[field], lib.FacetCount{Value: bucket.Value, Count: bucket.Count})
+		}
+	}
+	return
+}
@@ -0,0 +1,321 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package db
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SENERGY-Platform/analytics-operator-repo-v2/lib"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type filterKind int
+
+const (
+	filterString filterKind = iota
+	filterNumber
+	filterBool
+	filterTime
+)
+
+type filterField struct {
+	path string
+	kind filterKind
+}
+
+// filterFields is the allowlist of fields usable in filter expressions and their database path.
+var filterFields = map[string]filterField{
+	"name":               {path: "name", kind: filterString},
+	"deploymentType":     {path: "deploymentType", kind: filterString},
+	"pub":                {path: "pub", kind: filterBool},
+	"userId":             {path: "userId", kind: filterString},
+	"cost":               {path: "cost", kind: filterNumber},
+	"tags":               {path: "tags", kind: filterString},
+	"version":            {path: "version", kind: filterString},
+	"published":          {path: "published", kind: filterBool},
+	"dateCreated":        {path: "dateCreated", kind: filterTime},
+	"dateUpdated":        {path: "dateUpdated", kind: filterTime},
+	"inputs.name":        {path: "inputs.name", kind: filterString},
+	"inputs.type":        {path: "inputs.type", kind: filterString},
+	"outputs.name":       {path: "outputs.name", kind: filterString},
+	"outputs.type":       {path: "outputs.type", kind: filterString},
+	"config_values.name": {path: "config_values.name", kind: filterString},
+}
+
+// filterOperators maps RSQL comparison operators to mongo operators, longer operators come first.
+var filterOperators = []struct {
+	rsql  string
+	mongo string
+}{
+	{"=out=", "$nin"},
+	{"=in=", "$in"},
+	{"=lt=", "$lt"},
+	{"=le=", "$lte"},
+	{"=gt=", "$gt"},
+	{"=ge=", "$gte"},
+	{"==", "$eq"},
+	{"!=", "$ne"},
+	{"<=", "$lte"},
+	{">=", "$gte"},
+	{"<", "$lt"},
+	{">", "$gt"},
+}
+
+var filterKindOperators = map[filterKind][]string{
+	filterString: {"$eq", "$ne", "$in", "$nin"},
+	filterBool:   {"$eq", "$ne"},
+	filterNumber: {"$eq", "$ne", "$in", "$nin", "$lt", "$lte", "$gt", "$gte"},
+	filterTime:   {"$eq", "$ne", "$lt", "$lte", "$gt", "$gte"},
+}
+
+// parseFilter translates an RSQL expression into a mongo query.
+// Constraints are combined with ";" (and) and "," (or), parentheses group them.
+// Example: deploymentType==cloud;(cost=lt=10,pub==true);inputs.name=in=(value,ts)
+// Unquoted string values may contain "*" as a wildcard for == and !=.
+func parseFilter(expression string) (bson.M, error) {
+	p := &filterParser{input: expression}
+	query, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpaces()
+	if p.pos < len(p.input) {
+		return nil, p.error("unexpected " + strconv.Quote(p.input[p.pos:p.pos+1]))
+	}
+	return query, nil
+}
+
+type filterParser struct {
+	input string
+	pos   int
+}
+
+func (p *filterParser) error(msg string) error {
+	return lib.NewInvalidInputError(fmt.Errorf("invalid filter at position %d: %s", p.pos, msg))
+}
+
+func (p *filterParser) skipSpaces() {
+	for p.pos < len(p.input) && p.input[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+func (p *filterParser) consume(c byte) bool {
+	p.skipSpaces()
+	if p.pos < len(p.input) && p.input[p.pos] == c {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *filterParser) parseOr() (bson.M, error) {
+	var terms bson.A
+	for {
+		term, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, term)
+		if !p.consume(',') {
+			break
+		}
+	}
+	if len(terms) == 1 {
+		return terms[0].(bson.M), nil
+	}
+	return bson.M{"$or": terms}, nil
+}
+
+func (p *filterParser) parseAnd() (bson.M, error) {
+	var terms bson.A
+	for {
+		term, err := p.parseConstraint()
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, term)
+		if !p.consume(';') {
+			break
+		}
+	}
+	if len(terms) == 1 {
+		return terms[0].(bson.M), nil
+	}
+	return bson.M{"$and": terms}, nil
+}
+
+func (p *filterParser) parseConstraint() (bson.M, error) {
+	if p.consume('(') {
+		query, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.consume(')') {
+			return nil, p.error("missing )")
+		}
+		return query, nil
+	}
+	p.skipSpaces()
+	start := p.pos
+	for p.pos < len(p.input) && isSelectorChar(p.input[p.pos]) {
+		p.pos++
+	}
+	selector := p.input[start:p.pos]
+	if selector == "" {
+		return nil, p.error("expected field")
+	}
+	field, ok := filterFields[selector]
+	if !ok {
+		p.pos = start
+		return nil, p.error("unknown field " + strconv.Quote(selector) + ", allowed are " + strings.Join(filterFieldNames(), ", "))
+	}
+	p.skipSpaces()
+	operator := ""
+	for _, op := range filterOperators {
+		if strings.HasPrefix(p.input[p.pos:], op.rsql) {
+			operator = op.mongo
+			p.pos += len(op.rsql)
+			break
+		}
+	}
+	if operator == "" {
+		return nil, p.error("expected comparison operator after " + selector)
+	}
+	if !slices.Contains(filterKindOperators[field.kind], operator) {
+		return nil, p.error("operator not allowed for field " + selector)
+	}
+	if operator == "$in" || operator == "$nin" {
+		if !p.consume('(') {
+			return nil, p.error("expected ( after list operator")
+		}
+		var values bson.A
+		for {
+			raw, _, err := p.parseValue()
+			if err != nil {
+				return nil, err
+			}
+			value, err := p.convert(field, raw)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+			if !p.consume(',') {
+				break
+			}
+		}
+		if !p.consume(')') {
+			return nil, p.error("missing )")
+		}
+		return bson.M{field.path: bson.M{operator: values}}, nil
+	}
+	raw, quoted, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	if field.kind == filterString && !quoted && strings.Contains(raw, "*") {
+		parts := strings.Split(raw, "*")
+		for i := range parts {
+			parts[i] = regexp.QuoteMeta(parts[i])
+		}
+		pattern := bson.Regex{Pattern: "^" + strings.Join(parts, ".*") + "$"}
+		if operator == "$ne" {
+			return bson.M{field.path: bson.M{"$not": pattern}}, nil
+		}
+		return bson.M{field.path: pattern}, nil
+	}
+	value, err := p.convert(field, raw)
+	if err != nil {
+		return nil, err
+	}
+	return bson.M{field.path: bson.M{operator: value}}, nil
+}
+
+func (p *filterParser) parseValue() (value string, quoted bool, err error) {
+	p.skipSpaces()
+	if p.pos >= len(p.input) {
+		return "", false, p.error("expected value")
+	}
+	if q := p.input[p.pos]; q == '"' || q == '\'' {
+		p.pos++
+		var sb strings.Builder
+		for p.pos < len(p.input) {
+			c := p.input[p.pos]
+			p.pos++
+			if c == '\\' && p.pos < len(p.input) {
+				sb.WriteByte(p.input[p.pos])
+				p.pos++
+				continue
+			}
+			if c == q {
+				return sb.String(), true, nil
+			}
+			sb.WriteByte(c)
+		}
+		return "", false, p.error("unterminated string")
+	}
+	start := p.pos
+	for p.pos < len(p.input) && !strings.ContainsRune(" ;,()\"'", rune(p.input[p.pos])) {
+		p.pos++
+	}
+	if p.pos == start {
+		return "", false, p.error("expected value")
+	}
+	return p.input[start:p.pos], false, nil
+}
+
+func (p *filterParser) convert(field filterField, raw string) (any, error) {
+	switch field.kind {
+	case filterNumber:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, p.error(strconv.Quote(raw) + " is not a number")
+		}
+		return f, nil
+	case filterBool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, p.error(strconv.Quote(raw) + " is not a boolean")
+		}
+		return b, nil
+	case filterTime:
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return nil, p.error(strconv.Quote(raw) + " is not an RFC 3339 timestamp")
+		}
+		return t, nil
+	}
+	return raw, nil
+}
+
+func isSelectorChar(c byte) bool {
+	return c == '.' || c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+func filterFieldNames() []string {
+	names := make([]string, 0, len(filterFields))
+	for name := range filterFields {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
@@ -0,0 +1,139 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package db
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SENERGY-Platform/analytics-operator-repo-v2/lib"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestParseFilter(t *testing.T) {
+	cases := []struct {
+		expression string
+		expected   bson.M
+	}{
+		{"name==abc", bson.M{"name": bson.M{"$eq": "abc"}}},
+		{" name == abc ", bson.M{"name": bson.M{"$eq": "abc"}}},
+		{"name!=abc", bson.M{"name": bson.M{"$ne": "abc"}}},
+		{"cost=lt=10", bson.M{"cost": bson.M{"$lt": 10.0}}},
+		{"cost=le=10", bson.M{"cost": bson.M{"$lte": 10.0}}},
+		{"cost=gt=1.5", bson.M{"cost": bson.M{"$gt": 1.5}}},
+		{"cost=ge=1", bson.M{"cost": bson.M{"$gte": 1.0}}},
+		{"cost<10", bson.M{"cost": bson.M{"$lt": 10.0}}},
+		{"cost<=10", bson.M{"cost": bson.M{"$lte": 10.0}}},
+		{"cost>10", bson.M{"cost": bson.M{"$gt": 10.0}}},
+		{"cost>=10", bson.M{"cost": bson.M{"$gte": 10.0}}},
+		{"pub==true", bson.M{"pub": bson.M{"$eq": true}}},
+		{"published!=false", bson.M{"published": bson.M{"$ne": false}}},
+		{"dateCreated=ge=2025-01-02T03:04:05Z", bson.M{"dateCreated": bson.M{"$gte": time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)}}},
+		{"inputs.name=in=(value,ts)", bson.M{"inputs.name": bson.M{"$in": bson.A{"value", "ts"}}}},
+		{"tags=out=( a , 'b c' )", bson.M{"tags": bson.M{"$nin": bson.A{"a", "b c"}}}},
+		{"cost=in=(1,2)", bson.M{"cost": bson.M{"$in": bson.A{1.0, 2.0}}}},
+		{"name==a;pub==false", bson.M{"$and": bson.A{
+			bson.M{"name": bson.M{"$eq": "a"}},
+			bson.M{"pub": bson.M{"$eq": false}},
+		}}},
+		{"name==a,name==b", bson.M{"$or": bson.A{
+			bson.M{"name": bson.M{"$eq": "a"}},
+			bson.M{"name": bson.M{"$eq": "b"}},
+		}}},
+		{"name==a,name==b;pub==true", bson.M{"$or": bson.A{
+			bson.M{"name": bson.M{"$eq": "a"}},
+			bson.M{"$and": bson.A{
+				bson.M{"name": bson.M{"$eq": "b"}},
+				bson.M{"pub": bson.M{"$eq": true}},
+			}},
+		}}},
+		{"deploymentType==cloud;(cost=lt=10,pub==true)", bson.M{"$and": bson.A{
+			bson.M{"deploymentType": bson.M{"$eq": "cloud"}},
+			bson.M{"$or": bson.A{
+				bson.M{"cost": bson.M{"$lt": 10.0}},
+				bson.M{"pub": bson.M{"$eq": true}},
+			}},
+		}}},
+		{"((name==a))", bson.M{"name": bson.M{"$eq": "a"}}},
+		{"name==op*", bson.M{"name": bson.Regex{Pattern: "^op.*$"}}},
+		{"name!=*a.b", bson.M{"name": bson.M{"$not": bson.Regex{Pattern: `^.*a\.b$`}}}},
+		{"name=='op*'", bson.M{"name": bson.M{"$eq": "op*"}}},
+		{`name=="a,b;c(d)"`, bson.M{"name": bson.M{"$eq": "a,b;c(d)"}}},
+		{`name=="a\"b"`, bson.M{"name": bson.M{"$eq": `a"b`}}},
+		{`name=='it\'s'`, bson.M{"name": bson.M{"$eq": "it's"}}},
+		{`name=="a\\b"`, bson.M{"name": bson.M{"$eq": `a\b`}}},
+		{`name==""`, bson.M{"name": bson.M{"$eq": ""}}},
+	}
+	for _, c := range cases {
+		t.Run(c.expression, func(t *testing.T) {
+			query, err := parseFilter(c.expression)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(query, c.expected) {
+				t.Errorf("expected %v, got %v", c.expected, query)
+			}
+		})
+	}
+}
+
+func TestParseFilterErrors(t *testing.T) {
+	cases := []struct {
+		expression string
+		expected   string
+	}{
+		{"", "position 0: expected field"},
+		{"==a", "position 0: expected field"},
+		{"secret==1", `position 0: unknown field "secret", allowed are `},
+		{"name==a;readers.users==u1", `position 8: unknown field "readers.users"`},
+		{"$where==1", "position 0: expected field"},
+		{"name", "position 4: expected comparison operator after name"},
+		{"name=abc", "position 4: expected comparison operator after name"},
+		{"pub=lt=true", "position 7: operator not allowed for field pub"},
+		{"name>a", "position 5: operator not allowed for field name"},
+		{"dateCreated=in=(a)", "position 15: operator not allowed for field dateCreated"},
+		{"name==", "position 6: expected value"},
+		{"name==;pub==true", "position 6: expected value"},
+		{"cost==abc", `position 9: "abc" is not a number`},
+		{"pub==yes", `position 8: "yes" is not a boolean`},
+		{"dateCreated>2025-01-01", `position 22: "2025-01-01" is not an RFC 3339 timestamp`},
+		{`name=="abc`, "position 10: unterminated string"},
+		{"(name==a", "position 8: missing )"},
+		{"name=in=(a,b", "position 12: missing )"},
+		{"name=in=a", "position 8: expected ( after list operator"},
+		{"name==a)", `position 7: unexpected ")"`},
+		{"name==a b", `position 8: unexpected "b"`},
+	}
+	for _, c := range cases {
+		t.Run(c.expression, func(t *testing.T) {
+			_, err := parseFilter(c.expression)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			var invalid *lib.InvalidInputError
+			if !errors.As(err, &invalid) {
+				t.Errorf("expected invalid input error, got %T", err)
+			}
+			if !strings.Contains(err.Error(), c.expected) {
+				t.Errorf("expected %q in %q", c.expected, err.Error())
+			}
+		})
+	}
+}
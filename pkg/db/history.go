@@ -0,0 +1,115 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package db
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/SENERGY-Platform/analytics-operator-repo-v2/lib"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// DefaultHistoryLimit applies to history queries without limit.
+const DefaultHistoryLimit = 100
+
+// historyFilters maps the query arguments of the audit query to their database field.
+var historyFilters = map[string]string{
+	"operatorId": "operatorId",
+	"actor":      "actor",
+	"action":     "action",
+	"requestId":  "requestId",
+}
+
+// HistoryStore keeps every change of the operators, entries are never updated.
+type HistoryStore struct {
+	coll *mongo.Collection
+}
+
+func NewHistoryStore(coll *mongo.Collection) *HistoryStore {
+	return &HistoryStore{coll: coll}
+}
+
+func (s *HistoryStore) CreateIndexes() (err error) {
+	ctx, cf := getTimeoutContext(context.Background())
+	defer cf()
+	_, err = s.coll.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "operatorId", Value: 1}, {Key: "time", Value: -1}}},
+		{Keys: bson.D{{Key: "actor", Value: 1}, {Key: "time", Value: -1}}},
+		{Keys: bson.D{{Key: "requestId", Value: 1}}, Options: options.Index().SetSparse(true)},
+		{Keys: bson.D{{Key: "time", Value: -1}}},
+	})
+	return
+}
+
+// Insert stores the entry, entries already stored are ignored.
+func (s *HistoryStore) Insert(entry lib.HistoryEntry) error {
+	_, err := s.coll.InsertOne(context.TODO(), entry)
+	if mongo.IsDuplicateKeyError(err) {
+		return nil
+	}
+	return mongoError(err)
+}
+
+// Find lists history entries, latest first. Entries can be filtered by operatorId, actor, action, requestId and
+// a time range given by from and to as RFC 3339 timestamps. A non-empty operatorId argument restricts the query to that operator.
+func (s *HistoryStore) Find(operatorId string, args map[string][]string) (response lib.HistoryResponse, err error) {
+	req := bson.M{}
+	for arg, field := range historyFilters {
+		if val, ok := args[arg]; ok && val[0] != "" {
+			req[field] = val[0]
+		}
+	}
+	if operatorId != "" {
+		req["operatorId"] = operatorId
+	}
+	timeRange := bson.M{}
+	for arg, op := range map[string]string{"from": "$gte", "to": "$lt"} {
+		if val, ok := args[arg]; ok && val[0] != "" {
+			t, e := time.Parse(time.RFC3339, val[0])
+			if e != nil {
+				return response, lib.NewInvalidInputError(errors.New("invalid " + arg + " " + strconv.Quote(val[0]) + ", expected RFC 3339 timestamp"))
+			}
+			timeRange[op] = t
+		}
+	}
+	if len(timeRange) > 0 {
+		req["time"] = timeRange
+	}
+	limit := int64(DefaultHistoryLimit)
+	if val, ok := args["limit"]; ok {
+		limit, _ = strconv.ParseInt(val[0], 10, 64)
+	}
+	var skip int64
+	if val, ok := args["offset"]; ok {
+		skip, _ = strconv.ParseInt(val[0], 10, 64)
+	}
+	cur, err := s.coll.Find(context.TODO(), req, options.Find().SetSort(bson.D{{Key: "time", Value: -1}, {Key: "_id", Value: -1}}).SetLimit(limit).SetSkip(skip))
+	if err != nil {
+		return response, mongoError(err)
+	}
+	response.Entries = make([]lib.HistoryEntry, 0)
+	if err = cur.All(context.TODO(), &response.Entries); err != nil {
+		return response, mongoError(err)
+	}
+	response.Total, err = s.coll.CountDocuments(context.TODO(), req)
+	return response, mongoError(err)
+}
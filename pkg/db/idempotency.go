@@ -0,0 +1,157 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/SENERGY-Platform/analytics-operator-repo-v2/lib"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const (
+	idempotencyPending = "pending"
+	idempotencyDone    = "done"
+)
+
+// idempotencyLease is the time a pending reservation blocks other requests with the same key.
+// Afterward the request that reserved it is assumed to have failed without releasing it, and a retry may take it over.
+const idempotencyLease = 30 * time.Second
+
+type IdempotencyRecord struct {
+	UserId       string    `bson:"userId"`
+	Key          string    `bson:"key"`
+	RequestHash  string    `bson:"requestHash"`
+	Status       string    `bson:"status"`
+	OperatorId   string    `bson:"operatorId,omitempty"`
+	DateCreated  time.Time `bson:"dateCreated"`
+	DateReserved time.Time `bson:"dateReserved"`
+	ExpiresAt    time.Time `bson:"expiresAt"`
+}
+
+// IdempotencyStore remembers the result of requests sent with an Idempotency-Key.
+// Records expire after the TTL, a key is scoped to the user who sent it.
+type IdempotencyStore struct {
+	coll *mongo.Collection
+	ttl  time.Duration
+}
+
+func NewIdempotencyStore(coll *mongo.Collection, ttl time.Duration) *IdempotencyStore {
+	return &IdempotencyStore{coll: coll, ttl: ttl}
+}
+
+// legacyIdempotencyTTLIndex expired records by dateCreated, its options had to change with the configured TTL.
+const legacyIdempotencyTTLIndex = "dateCreated_1"
+
+// CreateIndexes creates the indexes of the store. Records expire at their expiresAt field, so the index stays the same
+// when the TTL is changed. Records stored before are given an expiry and the former TTL index is dropped.
+func (s *IdempotencyStore) CreateIndexes() (err error) {
+	ctx, cf := getTimeoutContext(context.Background())
+	defer cf()
+	_, err = s.coll.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "userId", Value: 1}, {Key: "key", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "expiresAt", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	})
+	if err != nil {
+		return
+	}
+	_, err = s.coll.UpdateMany(ctx, bson.M{"expiresAt": bson.M{"$exists": false}}, bson.A{
+		bson.M{"$set": bson.M{"expiresAt": bson.M{"$add": bson.A{"$dateCreated", s.ttl.Milliseconds()}}}},
+	})
+	if err != nil {
+		return
+	}
+	err = s.coll.Indexes().DropOne(ctx, legacyIdempotencyTTLIndex)
+	var cmdErr mongo.CommandError
+	if errors.As(err, &cmdErr) && (cmdErr.Code == mongoCodeIndexNotFound || cmdErr.Code == mongoCodeNamespaceNotFound) {
+		err = nil
+	}
+	return
+}
+
+// Reserve claims the key for a request. If the key is known already, the stored record is returned instead.
+// Reusing a key for a different request or while the first request is still running is a conflict.
+// A pending reservation older than the lease is taken over.
+func (s *IdempotencyStore) Reserve(userId string, key string, requestHash string) (record IdempotencyRecord, reserved bool, err error) {
+	now := time.Now()
+	record = IdempotencyRecord{
+		UserId:       userId,
+		Key:          key,
+		RequestHash:  requestHash,
+		Status:       idempotencyPending,
+		DateCreated:  now,
+		DateReserved: now,
+		ExpiresAt:    now.Add(s.ttl),
+	}
+	_, err = s.coll.InsertOne(context.TODO(), record)
+	if err == nil {
+		return record, true, nil
+	}
+	if !mongo.IsDuplicateKeyError(err) {
+		return record, false, mongoError(err)
+	}
+	err = s.coll.FindOne(context.TODO(), bson.M{"userId": userId, "key": key}).Decode(&record)
+	if err != nil {
+		return record, false, mongoError(err)
+	}
+	if record.RequestHash != requestHash {
+		return record, false, lib.NewConflictError(errors.New("idempotency key was used for a different request"))
+	}
+	if record.Status != idempotencyDone {
+		var res *mongo.UpdateResult
+		res, err = s.coll.UpdateOne(context.TODO(), bson.M{
+			"userId":       userId,
+			"key":          key,
+			"status":       idempotencyPending,
+			"dateReserved": bson.M{"$not": bson.M{"$gt": now.Add(-idempotencyLease)}},
+		}, bson.M{"$set": bson.M{"dateReserved": now}})
+		if err != nil {
+			return record, false, mongoError(err)
+		}
+		if res.ModifiedCount == 0 {
+			return record, false, lib.NewConflictError(errors.New("request with this idempotency key is still in progress"))
+		}
+		record.DateReserved = now
+		return record, true, nil
+	}
+	return record, false, nil
+}
+
+// Complete stores the created operator, so repeated requests return it.
+func (s *IdempotencyStore) Complete(userId string, key string, operatorId string) (err error) {
+	_, err = s.coll.UpdateOne(context.TODO(), bson.M{"userId": userId, "key": key}, bson.M{"$set": bson.M{
+		"status":     idempotencyDone,
+		"operatorId": operatorId,
+	}})
+	return mongoError(err)
+}
+
+// Release removes the reservation of a failed request, so it can be retried with the same key.
+func (s *IdempotencyStore) Release(userId string, key string) (err error) {
+	_, err = s.coll.DeleteOne(context.TODO(), bson.M{"userId": userId, "key": key, "status": idempotencyPending})
+	return mongoError(err)
+}
@@ -0,0 +1,96 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package db
+
+import (
+	"errors"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/SENERGY-Platform/analytics-operator-repo-v2/lib"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const ViewSummary = "summary"
+
+// projectionFields maps the selectable fields to their database path.
+var projectionFields = map[string]string{
+	"_id":            "_id",
+	"name":           "name",
+	"image":          "image",
+	"description":    "description",
+	"tags":           "tags",
+	"deploymentType": "deploymentType",
+	"cost":           "cost",
+	"userId":         "userId",
+	"pub":            "pub",
+	"version":        "version",
+	"published":      "published",
+	"config_values":  "config_values",
+	"inputs":         "inputs",
+	"outputs":        "outputs",
+	"dateCreated":    "dateCreated",
+	"dateUpdated":    "dateUpdated",
+}
+
+// summaryFields leaves out the ports and config values, which make up most of an operator.
+var summaryFields = []string{"name", "description", "tags", "deploymentType", "cost", "userId", "pub", "version", "published", "dateUpdated"}
+
+// defaultProjection hides the readers projection and the permissions of deleted operators, they are internal only.
+var defaultProjection = bson.M{"readers": 0, trashPermissionsField: 0}
+
+// parseProjection builds the projection for the fields or view argument.
+// The id, the revision and the sort keys are always included, the sort keys are needed to create cursors.
+func parseProjection(args map[string][]string, sort bson.D) (projection bson.M, err error) {
+	var fields []string
+	view := ""
+	if val, ok := args["view"]; ok {
+		view = val[0]
+	}
+	switch view {
+	case "":
+	case ViewSummary:
+		fields = summaryFields
+	default:
+		return nil, lib.NewInvalidInputError(errors.New("invalid view " + strconv.Quote(view) + ", allowed is " + ViewSummary))
+	}
+	if val, ok := args["fields"]; ok && val[0] != "" {
+		if view != "" {
+			return nil, lib.NewInvalidInputError(errors.New("fields and view can not be combined"))
+		}
+		fields = strings.Split(val[0], ",")
+	}
+	if len(fields) == 0 {
+		return defaultProjection, nil
+	}
+	projection = bson.M{"_id": 1, "revision": 1}
+	for _, field := range fields {
+		path, ok := projectionFields[strings.TrimSpace(field)]
+		if !ok {
+			return nil, lib.NewInvalidInputError(errors.New("invalid field " + strconv.Quote(field) + ", allowed are " + strings.Join(slices.Sorted(maps.Keys(projectionFields)), ", ")))
+		}
+		projection[path] = 1
+	}
+	for _, key := range sort {
+		if _, meta := key.Value.(bson.M); !meta {
+			projection[key.Key] = 1
+		}
+	}
+	return
+}
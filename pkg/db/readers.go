@@ -0,0 +1,176 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package db
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/SENERGY-Platform/analytics-operator-repo-v2/pkg/util"
+	permV2Client "github.com/SENERGY-Platform/permissions-v2/pkg/client"
+	"github.com/SENERGY-Platform/service-commons/pkg/jwt"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const readersSyncPageSize = 1000
+
+// readers is a projection of the principals with read permission, stored with every operator.
+// It allows listing, sorting and counting accessible operators with a single indexed query.
+// Permissions-v2 stays the source of truth for all permission checks.
+type readers struct {
+	Users  []string `bson:"users"`
+	Groups []string `bson:"groups"`
+	Roles  []string `bson:"roles"`
+}
+
+func toReaders(permissions permV2Client.ResourcePermissions) readers {
+	return readers{
+		Users:  readPrincipals(permissions.UserPermissions),
+		Groups: readPrincipals(permissions.GroupPermissions),
+		Roles:  readPrincipals(permissions.RolePermissions),
+	}
+}
+
+func readPrincipals(permissions map[string]permV2Client.PermissionsMap) []string {
+	principals := make([]string, 0, len(permissions))
+	for _, principal := range slices.Sorted(maps.Keys(permissions)) {
+		if permissions[principal].Read {
+			principals = append(principals, principal)
+		}
+	}
+	return principals
+}
+
+// readersIndexes support listing by name, they use the collation of list queries.
+// Mongo only uses indexes for an $or if every clause is indexed, so each clause of the readers filter needs one.
+func readersIndexes() []mongo.IndexModel {
+	return []mongo.IndexModel{
+		{Keys: bson.D{{Key: "readers.users", Value: 1}, {Key: "name", Value: 1}}, Options: options.Index().SetCollation(listCollation)},
+		{Keys: bson.D{{Key: "readers.groups", Value: 1}, {Key: "name", Value: 1}}, Options: options.Index().SetCollation(listCollation)},
+		{Keys: bson.D{{Key: "readers.roles", Value: 1}, {Key: "name", Value: 1}}, Options: options.Index().SetCollation(listCollation)},
+		{Keys: bson.D{{Key: "userId", Value: 1}, {Key: "name", Value: 1}}, Options: options.Index().SetCollation(listCollation)},
+		{Keys: bson.D{{Key: "pub", Value: 1}, {Key: "name", Value: 1}}, Options: options.Index().SetCollation(listCollation)},
+	}
+}
+
+// textIndex covers all fields used by the full-text search, names weigh the most.
+// Stemming is disabled, operator names and ports are technical terms.
+func textIndex() mongo.IndexModel {
+	return mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "name", Value: "text"},
+			{Key: "tags", Value: "text"},
+			{Key: "description", Value: "text"},
+			{Key: "inputs.name", Value: "text"},
+			{Key: "outputs.name", Value: "text"},
+			{Key: "config_values.name", Value: "text"},
+		},
+		Options: options.Index().
+			SetName("operator_text").
+			SetDefaultLanguage("none").
+			SetWeights(bson.D{
+				{Key: "name", Value: 10},
+				{Key: "tags", Value: 5},
+				{Key: "description", Value: 2},
+				{Key: "inputs.name", Value: 1},
+				{Key: "outputs.name", Value: 1},
+				{Key: "config_values.name", Value: 1},
+			}),
+	}
+}
+
+// readersFilter matches all operators readable by the user, groups and roles are taken from the token like permissions-v2 does.
+func readersFilter(userId string, auth string) bson.A {
+	filter := bson.A{
+		bson.M{"userId": userId},
+		bson.M{"pub": true},
+		bson.M{"readers.users": userId},
+	}
+	if auth == "" {
+		return filter
+	}
+	token, err := jwt.Parse(auth)
+	if err != nil {
+		return filter
+	}
+	if groups := token.GetGroups(); len(groups) > 0 {
+		filter = append(filter, bson.M{"readers.groups": bson.M{"$in": groups}})
+	}
+	if roles := token.GetRoles(); len(roles) > 0 {
+		filter = append(filter, bson.M{"readers.roles": bson.M{"$in": roles}})
+	}
+	return filter
+}
+
+// setPermission stores the permissions in permissions-v2 and updates the readers projection of the operator.
+func (r *MongoRepo) setPermission(id string, permissions permV2Client.ResourcePermissions) (result permV2Client.ResourcePermissions, err error) {
+	result, err, code := r.perm.SetPermission(permV2Client.InternalAdminToken, PermV2InstanceTopic, id, permissions)
+	if err != nil {
+		return result, permError(err, code)
+	}
+	return result, r.setReaders(id, result)
+}
+
+func (r *MongoRepo) setReaders(id string, permissions permV2Client.ResourcePermissions) (err error) {
+	objId, err := parseObjectID(id)
+	if err != nil {
+		return
+	}
+	_, err = r.coll.UpdateByID(context.TODO(), objId, bson.M{"$set": bson.M{"readers": toReaders(permissions)}})
+	return mongoError(err)
+}
+
+// SyncReaders rebuilds the readers projection from permissions-v2.
+// It picks up permission changes that did not pass through this service.
+func (r *MongoRepo) SyncReaders() (err error) {
+	var count int
+	for offset := int64(0); ; offset += readersSyncPageSize {
+		resources, err, code := r.perm.ListResourcesWithAdminPermission(permV2Client.InternalAdminToken, PermV2InstanceTopic, permV2Client.ListOptions{
+			Limit:  readersSyncPageSize,
+			Offset: offset,
+		})
+		if err != nil {
+			return permError(err, code)
+		}
+		models := make([]mongo.WriteModel, 0, len(resources))
+		for _, resource := range resources {
+			objId, err := bson.ObjectIDFromHex(resource.Id)
+			if err != nil {
+				continue
+			}
+			models = append(models, mongo.NewUpdateOneModel().
+				SetFilter(bson.M{"_id": objId}).
+				SetUpdate(bson.M{"$set": bson.M{"readers": toReaders(resource.ResourcePermissions)}}))
+		}
+		if len(models) > 0 {
+			_, err = r.coll.BulkWrite(context.TODO(), models)
+			if err != nil {
+				return mongoError(err)
+			}
+		}
+		count += len(resources)
+		if len(resources) < readersSyncPageSize {
+			break
+		}
+	}
+	util.Logger.Debug(fmt.Sprintf("synced readers of %d operators", count))
+	return
+}
@@ -21,6 +21,7 @@ import (
 	"errors"
 	"fmt"
 	"maps"
+	"net/http"
 	"slices"
 	"strconv"
 	"strings"
@@ -36,25 +37,45 @@ import (
 )
 
 type OperatorRepository interface {
-	InsertOperator(operator lib.Operator) (err error)
-	UpdateOperator(id string, operator lib.Operator, userId string, auth string) (err error)
-	DeleteOperator(id string, userId string, admin bool, auth string) (err error)
-	DeleteOperators(ids []string, userId string, admin bool, auth string) (err error)
+	InsertOperator(operator lib.Operator) (result lib.Operator, err error)
+	UpdateOperator(id string, operator lib.Operator, userId string, revision *int64, auth string) (err error)
+	DeleteOperator(id string, userId string, revision *int64, admin bool, auth string) (err error)
+	DeleteOperators(ids []string, userId string, admin bool, dryRun bool, auth string) (response lib.BatchDeleteResponse, err error)
 	All(userId string, admin bool, args map[string][]string, auth string) (response lib.OperatorResponse, err error)
-	FindOperator(id string, userId string, auth string) (flow lib.Operator, err error)
+	FindOperator(id string, userId string, args map[string][]string, auth string) (flow lib.Operator, err error)
+	FindOperatorById(id string) (operator lib.Operator, err error)
+	FindOperatorPermissionsById(id string) (permissions lib.OperatorPermissions, err error)
+	FindOperators(ids []string, userId string, args map[string][]string, auth string) (response lib.BatchGetResponse, err error)
+	FindWritableOperators(ids []string, filter string, userId string, auth string) (operators []lib.Operator, results []lib.ItemStatus, err error)
+	PublishOperator(id string, userId string, revision *int64, auth string) (err error)
+	FindOperatorVersions(id string, userId string, auth string) (response lib.OperatorVersionsResponse, err error)
+	FindOperatorVersion(id string, version string, userId string, auth string) (response lib.OperatorVersion, err error)
+	FindOperatorPermissions(id string, userId string, auth string) (permissions lib.OperatorPermissions, err error)
+	SetOperatorPermissions(id string, permissions lib.OperatorPermissions, userId string, auth string) (result lib.OperatorPermissions, err error)
+	TransferOperator(id string, newUserId string, userId string, auth string) (err error)
+	ReassignOperators(fromUserId string, toUserId string) (response lib.ReassignResponse, err error)
+	AdminTransferOperator(id string, newUserId string) (err error)
+	FindUserOperators(userId string) (operators []lib.Operator, err error)
+	IsOperatorShared(operator lib.Operator) (shared bool, err error)
+	RemoveUserPermissions(userId string, exceptIds []string) (changes []PermissionsChange, err error)
+	SyncReaders() (err error)
+	FindTrashedOperators(userId string, admin bool, args map[string][]string) (response lib.OperatorResponse, err error)
+	RestoreOperator(id string, userId string, admin bool) (operator lib.Operator, err error)
+	PurgeTrash(before time.Time, beforePurge func(operator lib.Operator) error) (operators []lib.Operator, err error)
 }
 
 type MongoRepo struct {
-	perm permV2Client.Client
-	coll *mongo.Collection
+	perm        permV2Client.Client
+	coll        *mongo.Collection
+	versionColl *mongo.Collection
 }
 
-func NewMongoRepo(perm permV2Client.Client, coll *mongo.Collection) *MongoRepo {
+func NewMongoRepo(perm permV2Client.Client, coll *mongo.Collection, versionColl *mongo.Collection) *MongoRepo {
 	_, err, _ := perm.SetTopic(permV2Client.InternalAdminToken, permV2Client.Topic{
 		Id: PermV2InstanceTopic,
 		DefaultPermissions: permV2Client.ResourcePermissions{
 			RolePermissions: map[string]permV2Model.PermissionsMap{
-				"admin": {
+				AdminRole: {
 					Read:         true,
 					Write:        true,
 					Execute:      true,
@@ -66,7 +87,68 @@ func NewMongoRepo(perm permV2Client.Client, coll *mongo.Collection) *MongoRepo {
 	if err != nil {
 		return nil
 	}
-	return &MongoRepo{perm: perm, coll: coll}
+	return &MongoRepo{perm: perm, coll: coll, versionColl: versionColl}
+}
+
+func (r *MongoRepo) CreateIndexes() (err error) {
+	ctx, cf := getTimeoutContext(context.Background())
+	defer cf()
+	_, err = r.versionColl.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "operatorId", Value: 1}, {Key: "version", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return
+	}
+	_, err = r.coll.Indexes().CreateMany(ctx, append(append(readersIndexes(), trashIndexes()...), textIndex()))
+	return
+}
+
+// MigrateOperatorVersions assigns the initial version to operators stored before versioning existed.
+// These operators are already in use, so their initial version is published right away.
+// Versions are upserted, so a migration interrupted between both writes is completed on the next start.
+func (r *MongoRepo) MigrateOperatorVersions() (err error) {
+	cur, err := r.coll.Find(context.TODO(), bson.M{"version": bson.M{"$in": bson.A{nil, ""}}})
+	if err != nil {
+		return mongoError(err)
+	}
+	var operators []lib.Operator
+	err = cur.All(context.TODO(), &operators)
+	if err != nil {
+		return mongoError(err)
+	}
+	for _, operator := range operators {
+		operator.Version = lib.InitialVersion
+		operator.Published = true
+		_, err = r.versionColl.UpdateOne(context.TODO(),
+			bson.M{"operatorId": operator.Id.Hex(), "version": operator.Version},
+			bson.M{"$setOnInsert": newVersion(operator)},
+			options.UpdateOne().SetUpsert(true))
+		if err != nil {
+			return mongoError(err)
+		}
+		_, err = r.coll.UpdateByID(context.TODO(), operator.Id, bson.M{"$set": bson.M{
+			"version":   operator.Version,
+			"published": operator.Published,
+		}})
+		if err != nil {
+			return mongoError(err)
+		}
+		util.Logger.Debug(fmt.Sprintf("%s migrated to version %s", operator.Id.Hex(), operator.Version))
+	}
+	return
+}
+
+// MigrateOperatorRevisions assigns the first revision to operators stored before revisions existed.
+func (r *MongoRepo) MigrateOperatorRevisions() (err error) {
+	res, err := r.coll.UpdateMany(context.TODO(), bson.M{"revision": bson.M{"$exists": false}}, bson.M{"$set": bson.M{"revision": int64(1)}})
+	if err != nil {
+		return mongoError(err)
+	}
+	if res.ModifiedCount > 0 {
+		util.Logger.Debug(fmt.Sprintf("%d operators migrated to revision 1", res.ModifiedCount))
+	}
+	return
 }
 
 func (r *MongoRepo) ValidateOperatorPermissions() (err error) {
@@ -95,13 +177,22 @@ func (r *MongoRepo) ValidateOperatorPermissions() (err error) {
 		dbIds = append(dbIds, operatorId)
 		resource, ok := permResourceMap[operatorId]
 		if ok {
-			permissions.UserPermissions = resource.ResourcePermissions.UserPermissions
-			permissions.GroupPermissions = resource.GroupPermissions
-			permissions.RolePermissions = resource.ResourcePermissions.RolePermissions
+			if resource.ResourcePermissions.UserPermissions != nil {
+				permissions.UserPermissions = resource.ResourcePermissions.UserPermissions
+			}
+			if resource.GroupPermissions != nil {
+				permissions.GroupPermissions = resource.GroupPermissions
+			}
+			if resource.ResourcePermissions.RolePermissions != nil {
+				permissions.RolePermissions = resource.ResourcePermissions.RolePermissions
+			}
+			if _, public := permissions.RolePermissions[PublicRole]; public != operator.Pub {
+				util.Logger.Debug(fmt.Sprintf("%s public permission differs from pub flag, now repaired", operatorId))
+			}
 		}
 		SetDefaultPermissions(operator, permissions)
 
-		_, err, _ = r.perm.SetPermission(permV2Client.InternalAdminToken, PermV2InstanceTopic, operatorId, permissions)
+		_, err = r.setPermission(operatorId, permissions)
 		if err != nil {
 			return
 		}
@@ -120,9 +211,15 @@ func (r *MongoRepo) ValidateOperatorPermissions() (err error) {
 	return
 }
 
-func (r *MongoRepo) InsertOperator(operator lib.Operator) (err error) {
+func (r *MongoRepo) InsertOperator(operator lib.Operator) (result lib.Operator, err error) {
+	err = lib.ValidateConfigDefinitions(operator.Config)
+	if err != nil {
+		return
+	}
 	operator.DateCreated = time.Now()
 	operator.DateUpdated = time.Now()
+	operator.DateDeleted = nil
+	operator.DeletedBy = ""
 	permissions := permV2Client.ResourcePermissions{
 		GroupPermissions: map[string]permV2Client.PermissionsMap{},
 		UserPermissions:  map[string]permV2Client.PermissionsMap{},
@@ -132,84 +229,481 @@ func (r *MongoRepo) InsertOperator(operator lib.Operator) (err error) {
 	if operator.Id != nil {
 		operator.Id = nil
 	}
-	result, err := r.coll.InsertOne(context.TODO(), operator)
+	if operator.Version == "" {
+		operator.Version = lib.InitialVersion
+	}
+	if _, err = lib.ParseSemVer(operator.Version); err != nil {
+		return
+	}
+	// like every new version, the first one is a draft until it is published
+	operator.Published = false
+	operator.Revision = 1
+	res, err := r.coll.InsertOne(context.TODO(), operator)
 	if err != nil {
-		return err
+		return result, mongoError(err)
 	}
 
-	id := result.InsertedID.(bson.ObjectID).Hex()
-	_, err, _ = r.perm.SetPermission(permV2Client.InternalAdminToken, PermV2InstanceTopic, id, permissions)
-	return
-}
-
-func (r *MongoRepo) DeleteOperator(id string, userId string, admin bool, auth string) (err error) {
-	ok, err, _ := r.perm.CheckPermission(auth, PermV2InstanceTopic, id, permV2Client.Administrate)
+	objId := res.InsertedID.(bson.ObjectID)
+	operator.Id = &objId
+	err = r.insertVersion(operator)
 	if err != nil {
-		return err
+		return
 	}
-	if !ok {
-		return errors.New(MessageMissingRights)
+	_, err = r.setPermission(objId.Hex(), permissions)
+	if err != nil {
+		return
 	}
+	return operator, nil
+}
 
-	objID, err := bson.ObjectIDFromHex(id)
+// DeleteOperator moves an operator to the trash and removes its permissions until it is restored or purged.
+// Admin deletions skip the permission check of the user. If a revision is given, it has to match the stored one.
+func (r *MongoRepo) DeleteOperator(id string, userId string, revision *int64, admin bool, auth string) (err error) {
+	if admin {
+		_, err = parseObjectID(id)
+	} else {
+		_, err = r.checkPermission(auth, id, permV2Client.Administrate)
+	}
 	if err != nil {
 		return
 	}
-	req := bson.M{"_id": objID}
-	res := r.coll.FindOneAndDelete(context.TODO(), req)
-	if res.Err() != nil {
-		return res.Err()
+	d := &deletion{id: id}
+	err = r.trashOperator(d, userId, revision)
+	if err != nil {
+		if e := r.rollbackDeletions([]*deletion{d}); e != nil {
+			return errors.Join(err, e)
+		}
 	}
-	err, _ = r.perm.RemoveResource(auth, PermV2InstanceTopic, id)
 	return
 }
 
-func (r *MongoRepo) DeleteOperators(ids []string, userId string, admin bool, auth string) (err error) {
-	okArr, err, _ := r.perm.CheckMultiplePermissions(auth, PermV2InstanceTopic, ids, permV2Client.Administrate)
+// UpdateOperator updates the operator in place as long as its current version is unpublished.
+// Changes to a published version are stored as a new unpublished version, metadata changes are applied directly.
+// If a revision is given, it has to match the stored one. Concurrent updates are detected in any case.
+func (r *MongoRepo) UpdateOperator(id string, operator lib.Operator, userId string, revision *int64, auth string) (err error) {
+	objId, err := r.checkPermission(auth, id, permV2Client.Write)
+	if err != nil {
+		return
+	}
+	var current lib.Operator
+	err = r.coll.FindOne(context.TODO(), bson.M{"_id": objId, "dateDeleted": bson.M{"$exists": false}}).Decode(&current)
 	if err != nil {
-		return err
+		return mongoError(err)
 	}
-	for id, ok := range okArr {
-		if !ok {
-			return errors.New(MessageMissingRights + " id: " + id)
+	if revision != nil && *revision != current.Revision {
+		return lib.NewPreconditionFailedError(errors.New(MessageRevisionMismatch))
+	}
+	if userId != current.UserId {
+		// others only ever get the operator with secret defaults redacted
+		err = operator.KeepSecrets(current)
+		if err != nil {
+			return
 		}
 	}
-	var objID bson.ObjectID
-	for _, id := range ids {
-		objID, err = bson.ObjectIDFromHex(id)
+	err = lib.ValidateConfigDefinitions(operator.Config)
+	if err != nil {
+		return
+	}
+	operator.Id = &objId
+	operator.Revision = current.Revision
+	operator.UserId = current.UserId
+	operator.DateCreated = current.DateCreated
+	operator.DateUpdated = time.Now()
+	if operator.Pub != current.Pub {
+		err = r.setPublicPermission(operator)
 		if err != nil {
 			return
 		}
-		req := bson.M{"_id": objID}
-		res := r.coll.FindOneAndDelete(context.TODO(), req)
-		if res.Err() != nil {
-			return res.Err()
+		defer func() {
+			if err != nil {
+				if e := r.setPublicPermission(current); e != nil {
+					util.Logger.Error("error on reverting public permission", "error", e, "id", id)
+				}
+			}
+		}()
+	}
+
+	if current.Published {
+		if lib.VersionedContentEqual(current, operator) && (operator.Version == "" || operator.Version == current.Version) {
+			res := r.coll.FindOneAndUpdate(context.TODO(), bson.M{"_id": objId, "revision": current.Revision}, bson.M{
+				"$set": bson.M{
+					"name":        operator.Name,
+					"description": operator.Description,
+					"tags":        operator.Tags,
+					"cost":        operator.Cost,
+					"pub":         operator.Pub,
+					"dateUpdated": operator.DateUpdated,
+				},
+				"$inc": bson.M{"revision": 1},
+			})
+			return r.revisionError(objId, res.Err())
+		}
+		var prev, next lib.SemVer
+		prev, err = lib.ParseSemVer(current.Version)
+		if err != nil {
+			return
+		}
+		if operator.Version == "" || operator.Version == current.Version {
+			next = lib.NextVersion(prev, current, operator)
+		} else {
+			next, err = lib.ParseSemVer(operator.Version)
+			if err != nil {
+				return
+			}
+			err = lib.CheckVersionBump(prev, current, next, operator)
+			if err != nil {
+				return
+			}
 		}
-		err, _ = r.perm.RemoveResource(auth, PermV2InstanceTopic, id)
+		operator.Version = next.String()
+		operator.Published = false
+		err = r.insertVersion(operator)
 		if err != nil {
 			return
 		}
+		err = r.setOperator(operator)
+		if err != nil {
+			r.revertVersion(id, operator.Version, nil)
+		}
+		return
+	}
+
+	if operator.Version == "" {
+		operator.Version = current.Version
+	}
+	next, err := lib.ParseSemVer(operator.Version)
+	if err != nil {
+		return
+	}
+	base, found, err := r.latestPublishedVersion(id)
+	if err != nil {
+		return
+	}
+	if found {
+		var prev lib.SemVer
+		prev, err = lib.ParseSemVer(base.Version)
+		if err != nil {
+			return
+		}
+		err = lib.CheckVersionBump(prev, base.Operator, next, operator)
+		if err != nil {
+			return
+		}
+	}
+	operator.Published = false
+	draft, err := r.versionColl.FindOneAndDelete(context.TODO(), bson.M{"operatorId": id, "version": current.Version, "published": false}).Raw()
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return mongoError(err)
+	}
+	err = r.insertVersion(operator)
+	if err != nil {
+		r.revertVersion(id, "", draft)
+		return
+	}
+	err = r.setOperator(operator)
+	if err != nil {
+		r.revertVersion(id, operator.Version, draft)
 	}
 	return
 }
 
-func (r *MongoRepo) UpdateOperator(id string, operator lib.Operator, userId string, auth string) (err error) {
-	ok, err, _ := r.perm.CheckPermission(auth, PermV2InstanceTopic, id, permV2Client.Write)
+// revertVersion undoes the version changes of a failed update.
+// The unpublished version written by the update is deleted and the replaced draft is stored again.
+func (r *MongoRepo) revertVersion(id string, version string, draft bson.Raw) {
+	if version != "" {
+		if _, err := r.versionColl.DeleteOne(context.TODO(), bson.M{"operatorId": id, "version": version, "published": false}); err != nil {
+			util.Logger.Error("error on deleting version of failed update", "error", err, "id", id, "version", version)
+		}
+	}
+	if draft != nil {
+		if _, err := r.versionColl.InsertOne(context.TODO(), draft); err != nil {
+			util.Logger.Error("error on restoring draft version", "error", err, "id", id)
+		}
+	}
+}
+
+// PublishOperator makes the current version of an operator immutable.
+// If a revision is given, it has to match the stored one. Both writes are conditional, so a version replaced by a
+// concurrent update is never marked as published.
+func (r *MongoRepo) PublishOperator(id string, userId string, revision *int64, auth string) (err error) {
+	objId, err := r.checkPermission(auth, id, permV2Client.Write)
 	if err != nil {
-		return err
+		return
 	}
-	if !ok {
-		return errors.New(MessageMissingRights)
+	var current lib.Operator
+	err = r.coll.FindOne(context.TODO(), bson.M{"_id": objId, "dateDeleted": bson.M{"$exists": false}}).Decode(&current)
+	if err != nil {
+		return mongoError(err)
+	}
+	if revision != nil && *revision != current.Revision {
+		return lib.NewPreconditionFailedError(errors.New(MessageRevisionMismatch))
+	}
+	if current.Published {
+		return lib.NewConflictError(errors.New("version " + current.Version + " is already published"))
+	}
+	res := r.coll.FindOneAndUpdate(context.TODO(), bson.M{"_id": objId, "revision": current.Revision}, bson.M{
+		"$set": bson.M{"published": true},
+		"$inc": bson.M{"revision": 1},
+	})
+	if err = r.revisionError(objId, res.Err()); err != nil {
+		return
 	}
+	now := time.Now()
+	updated, err := r.versionColl.UpdateOne(context.TODO(), bson.M{"operatorId": id, "version": current.Version, "published": false}, bson.M{"$set": bson.M{
+		"published":          true,
+		"datePublished":      now,
+		"operator.published": true,
+	}})
+	if err == nil && updated.MatchedCount == 0 {
+		err = lib.NewPreconditionFailedError(errors.New(MessageRevisionMismatch))
+	}
+	if err != nil {
+		if _, e := r.coll.UpdateOne(context.TODO(), bson.M{"_id": objId, "revision": current.Revision + 1}, bson.M{
+			"$set": bson.M{"published": false},
+			"$inc": bson.M{"revision": 1},
+		}); e != nil {
+			util.Logger.Error("error on reverting published operator", "error", e, "id", id)
+		}
+		return mongoError(err)
+	}
+	return
+}
 
-	objId, err := bson.ObjectIDFromHex(id)
+func (r *MongoRepo) FindOperatorVersions(id string, userId string, auth string) (response lib.OperatorVersionsResponse, err error) {
+	_, err = r.checkPermission(auth, id, permV2Client.Read)
 	if err != nil {
 		return
 	}
-	operator.Id = &objId
-	res := r.coll.FindOneAndUpdate(context.TODO(), bson.M{"_id": objId}, bson.M{"$set": bson.M{
+	response.Versions, err = r.findVersions(bson.M{"operatorId": id})
+	if err != nil {
+		return
+	}
+	response.Total = int64(len(response.Versions))
+	return
+}
+
+func (r *MongoRepo) FindOperatorVersion(id string, version string, userId string, auth string) (response lib.OperatorVersion, err error) {
+	_, err = r.checkPermission(auth, id, permV2Client.Read)
+	if err != nil {
+		return
+	}
+	err = r.versionColl.FindOne(context.TODO(), bson.M{"operatorId": id, "version": version}).Decode(&response)
+	return response, mongoError(err)
+}
+
+func (r *MongoRepo) FindOperatorPermissions(id string, userId string, auth string) (permissions lib.OperatorPermissions, err error) {
+	_, err = r.checkPermission(auth, id, permV2Client.Administrate)
+	if err != nil {
+		return
+	}
+	resource, err, code := r.perm.GetResource(permV2Client.InternalAdminToken, PermV2InstanceTopic, id)
+	if err != nil {
+		return permissions, permError(err, code)
+	}
+	return toOperatorPermissions(resource.ResourcePermissions), nil
+}
+
+// SetOperatorPermissions replaces the user, group and role permissions of an operator.
+// The owner always keeps full rights and the public role is controlled by the pub flag only.
+func (r *MongoRepo) SetOperatorPermissions(id string, permissions lib.OperatorPermissions, userId string, auth string) (result lib.OperatorPermissions, err error) {
+	objId, err := r.checkPermission(auth, id, permV2Client.Administrate)
+	if err != nil {
+		return
+	}
+	var operator lib.Operator
+	err = r.coll.FindOne(context.TODO(), bson.M{"_id": objId, "dateDeleted": bson.M{"$exists": false}}).Decode(&operator)
+	if err != nil {
+		return result, mongoError(err)
+	}
+	if owner := permissions.UserPermissions[operator.UserId]; !owner.Read || !owner.Write || !owner.Execute || !owner.Administrate {
+		return result, lib.NewInvalidInputError(errors.New("the owner must keep all permissions"))
+	}
+	if _, public := permissions.RolePermissions[PublicRole]; public != operator.Pub {
+		return result, lib.NewInvalidInputError(errors.New("permissions of role " + PublicRole + " are controlled by the pub flag"))
+	}
+	resourcePermissions := fromOperatorPermissions(permissions)
+	SetDefaultPermissions(operator, resourcePermissions)
+	resourcePermissions, err = r.setPermission(id, resourcePermissions)
+	if err != nil {
+		return
+	}
+	return toOperatorPermissions(resourcePermissions), nil
+}
+
+// TransferOperator hands the ownership of an operator to another user, only the owner may do so.
+func (r *MongoRepo) TransferOperator(id string, newUserId string, userId string, auth string) (err error) {
+	objId, err := r.checkPermission(auth, id, permV2Client.Administrate)
+	if err != nil {
+		return
+	}
+	var operator lib.Operator
+	err = r.coll.FindOne(context.TODO(), bson.M{"_id": objId, "dateDeleted": bson.M{"$exists": false}}).Decode(&operator)
+	if err != nil {
+		return mongoError(err)
+	}
+	if operator.UserId != userId {
+		return lib.NewForbiddenError(errors.New("only the owner may transfer an operator"))
+	}
+	return r.transferOperator(operator, newUserId)
+}
+
+// ReassignOperators transfers all operators of a user to another user.
+// Failed transfers are reported per operator and do not stop the remaining transfers.
+func (r *MongoRepo) ReassignOperators(fromUserId string, toUserId string) (response lib.ReassignResponse, err error) {
+	cur, err := r.coll.Find(context.TODO(), bson.M{"userId": fromUserId, "dateDeleted": bson.M{"$exists": false}})
+	if err != nil {
+		return response, mongoError(err)
+	}
+	var operators []lib.Operator
+	err = cur.All(context.TODO(), &operators)
+	if err != nil {
+		return response, mongoError(err)
+	}
+	response.Transferred = make([]string, 0)
+	response.Failed = map[string]string{}
+	for _, operator := range operators {
+		if e := r.transferOperator(operator, toUserId); e != nil {
+			util.Logger.Error("error on transferring operator", "error", e, "id", operator.Id.Hex())
+			response.Failed[operator.Id.Hex()] = e.Error()
+			continue
+		}
+		response.Transferred = append(response.Transferred, operator.Id.Hex())
+	}
+	return
+}
+
+func (r *MongoRepo) AdminTransferOperator(id string, newUserId string) (err error) {
+	objId, err := parseObjectID(id)
+	if err != nil {
+		return
+	}
+	var operator lib.Operator
+	err = r.coll.FindOne(context.TODO(), bson.M{"_id": objId, "dateDeleted": bson.M{"$exists": false}}).Decode(&operator)
+	if err != nil {
+		return mongoError(err)
+	}
+	return r.transferOperator(operator, newUserId)
+}
+
+func (r *MongoRepo) FindUserOperators(userId string) (operators []lib.Operator, err error) {
+	cur, err := r.coll.Find(context.TODO(), bson.M{"userId": userId, "dateDeleted": bson.M{"$exists": false}})
+	if err != nil {
+		return nil, mongoError(err)
+	}
+	operators = make([]lib.Operator, 0)
+	err = cur.All(context.TODO(), &operators)
+	return operators, mongoError(err)
+}
+
+// IsOperatorShared reports whether anyone besides the owner, admins and the public role has been granted rights.
+func (r *MongoRepo) IsOperatorShared(operator lib.Operator) (shared bool, err error) {
+	resource, err, code := r.perm.GetResource(permV2Client.InternalAdminToken, PermV2InstanceTopic, operator.Id.Hex())
+	if err != nil {
+		return false, permError(err, code)
+	}
+	for user := range resource.UserPermissions {
+		if user != operator.UserId {
+			return true, nil
+		}
+	}
+	for role := range resource.RolePermissions {
+		if role != PublicRole && role != AdminRole {
+			return true, nil
+		}
+	}
+	return len(resource.GroupPermissions) > 0, nil
+}
+
+// PermissionsChange holds the permissions of an operator before and after a change.
+type PermissionsChange struct {
+	Id       string
+	Previous lib.OperatorPermissions
+	Current  lib.OperatorPermissions
+}
+
+// RemoveUserPermissions removes all grants of a user from operators owned by others.
+// Operators listed in exceptIds are skipped.
+func (r *MongoRepo) RemoveUserPermissions(userId string, exceptIds []string) (changes []PermissionsChange, err error) {
+	resources, err, code := r.perm.ListResourcesWithAdminPermission(permV2Client.InternalAdminToken, PermV2InstanceTopic, permV2Client.ListOptions{})
+	if err != nil {
+		return nil, permError(err, code)
+	}
+	for _, resource := range resources {
+		if _, ok := resource.UserPermissions[userId]; !ok || slices.Contains(exceptIds, resource.Id) {
+			continue
+		}
+		previous := toOperatorPermissions(resource.ResourcePermissions)
+		delete(resource.UserPermissions, userId)
+		_, err = r.setPermission(resource.Id, resource.ResourcePermissions)
+		if err != nil {
+			return
+		}
+		changes = append(changes, PermissionsChange{Id: resource.Id, Previous: previous, Current: toOperatorPermissions(resource.ResourcePermissions)})
+		util.Logger.Debug(fmt.Sprintf("removed permissions of %s from %s", userId, resource.Id))
+	}
+	return
+}
+
+// transferOperator moves the owner grant in permissions-v2 first and updates the userId afterward.
+// If the database update fails, the previous permissions are restored.
+func (r *MongoRepo) transferOperator(operator lib.Operator, newUserId string) (err error) {
+	if newUserId == "" {
+		return lib.NewInvalidInputError(errors.New("missing new owner"))
+	}
+	id := operator.Id.Hex()
+	resource, err, code := r.perm.GetResource(permV2Client.InternalAdminToken, PermV2InstanceTopic, id)
+	if err != nil {
+		return permError(err, code)
+	}
+	permissions := fromOperatorPermissions(toOperatorPermissions(resource.ResourcePermissions))
+	delete(permissions.UserPermissions, operator.UserId)
+	operator.UserId = newUserId
+	SetDefaultPermissions(operator, permissions)
+	_, err = r.setPermission(id, permissions)
+	if err != nil {
+		return
+	}
+	_, err = r.coll.UpdateByID(context.TODO(), operator.Id, bson.M{
+		"$set": bson.M{
+			"userId":      newUserId,
+			"dateUpdated": time.Now(),
+		},
+		"$inc": bson.M{"revision": 1},
+	})
+	if err != nil {
+		if _, e := r.setPermission(id, resource.ResourcePermissions); e != nil {
+			util.Logger.Error("error on restoring permissions", "error", e, "id", id)
+		}
+		return mongoError(err)
+	}
+	return
+}
+
+// setPublicPermission grants or revokes the public role permission according to the pub flag of the operator.
+func (r *MongoRepo) setPublicPermission(operator lib.Operator) (err error) {
+	id := operator.Id.Hex()
+	resource, err, code := r.perm.GetResource(permV2Client.InternalAdminToken, PermV2InstanceTopic, id)
+	if err != nil {
+		return permError(err, code)
+	}
+	permissions := resource.ResourcePermissions
+	if permissions.RolePermissions == nil {
+		permissions.RolePermissions = map[string]permV2Model.PermissionsMap{}
+	}
+	if permissions.UserPermissions == nil {
+		permissions.UserPermissions = map[string]permV2Client.PermissionsMap{}
+	}
+	SetDefaultPermissions(operator, permissions)
+	_, err = r.setPermission(id, permissions)
+	return
+}
+
+// setOperator stores the operator if it was not changed since it has been read at operator.Revision.
+func (r *MongoRepo) setOperator(operator lib.Operator) (err error) {
+	res := r.coll.FindOneAndUpdate(context.TODO(), bson.M{"_id": operator.Id, "revision": operator.Revision}, bson.M{"$inc": bson.M{"revision": 1}, "$set": bson.M{
 		"name":           operator.Name,
 		"description":    operator.Description,
+		"tags":           operator.Tags,
 		"image":          operator.Image,
 		"cost":           operator.Cost,
 		"deploymentType": operator.DeploymentType,
@@ -217,101 +711,497 @@ func (r *MongoRepo) UpdateOperator(id string, operator lib.Operator, userId stri
 		"inputs":         operator.Inputs,
 		"outputs":        operator.Outputs,
 		"config_values":  operator.Config,
+		"version":        operator.Version,
+		"published":      operator.Published,
+		"dateUpdated":    operator.DateUpdated,
 	}})
-	if res.Err() != nil {
-		return res.Err()
+	return r.revisionError(*operator.Id, res.Err())
+}
+
+func (r *MongoRepo) insertVersion(operator lib.Operator) (err error) {
+	_, err = r.versionColl.InsertOne(context.TODO(), newVersion(operator))
+	return mongoError(err)
+}
+
+func newVersion(operator lib.Operator) lib.OperatorVersion {
+	version := lib.OperatorVersion{
+		OperatorId:  operator.Id.Hex(),
+		Version:     operator.Version,
+		Published:   operator.Published,
+		Operator:    operator,
+		DateCreated: time.Now(),
+	}
+	if operator.Published {
+		version.DatePublished = &version.DateCreated
+	}
+	return version
+}
+
+// findVersions returns the matching versions in ascending semver order.
+func (r *MongoRepo) findVersions(filter bson.M) (versions []lib.OperatorVersion, err error) {
+	cur, err := r.versionColl.Find(context.TODO(), filter)
+	if err != nil {
+		return nil, mongoError(err)
+	}
+	versions = make([]lib.OperatorVersion, 0)
+	err = cur.All(context.TODO(), &versions)
+	if err != nil {
+		return nil, mongoError(err)
 	}
+	slices.SortFunc(versions, func(a, b lib.OperatorVersion) int {
+		va, _ := lib.ParseSemVer(a.Version)
+		vb, _ := lib.ParseSemVer(b.Version)
+		return va.Compare(vb)
+	})
 	return
 }
 
+func (r *MongoRepo) latestPublishedVersion(id string) (version lib.OperatorVersion, found bool, err error) {
+	versions, err := r.findVersions(bson.M{"operatorId": id, "published": true})
+	if err != nil || len(versions) == 0 {
+		return
+	}
+	return versions[len(versions)-1], true, nil
+}
+
+// listQuery holds everything needed to load a list of operators besides the paging.
+type listQuery struct {
+	filter     bson.M
+	sort       bson.D
+	collation  *options.Collation
+	projection bson.M
+}
+
+// All lists the operators readable by the user together with the total.
+// Access is resolved with the readers projection, so no ids have to be fetched from permissions-v2.
+// Pages can be requested by offset or by the cursor returned with the previous page.
 func (r *MongoRepo) All(userId string, admin bool, args map[string][]string, auth string) (response lib.OperatorResponse, err error) {
-	opt := options.Find()
+	var sort bson.D
+	var skip, limit int64
+	relevance := false
 	for arg, value := range args {
-		if arg == "sort" {
-			ord := strings.Split(value[0], ":")
-			order := 1
-			if ord[1] == "desc" {
-				order = -1
-			}
-
-			sortFields := []string{"name"}
-			if slices.Contains(sortFields, ord[0]) {
-				opt.SetSort(bson.M{ord[0]: int64(order)})
+		if arg == "sort" && value[0] != "" {
+			sort, relevance, err = parseSort(value[0])
+			if err != nil {
+				return
 			}
 		}
 		if arg == "limit" {
-			limit, _ := strconv.ParseInt(value[0], 10, 64)
-			opt.SetLimit(limit)
+			limit, _ = strconv.ParseInt(value[0], 10, 64)
 		}
 		if arg == "offset" {
-			skip, _ := strconv.ParseInt(value[0], 10, 64)
-			opt.SetSkip(skip)
+			skip, _ = strconv.ParseInt(value[0], 10, 64)
 		}
 	}
 
-	var req = bson.M{}
-	ids := []bson.ObjectID{}
-	var stringIds []string
+	var req = bson.M{"dateDeleted": bson.M{"$exists": false}}
+	var terms []string
+	if val, ok := args["search"]; ok && strings.TrimSpace(val[0]) != "" {
+		req["$text"] = bson.M{"$search": val[0]}
+		terms = lib.SearchTerms(val[0])
+		if len(sort) == 0 {
+			relevance = true
+			sort = bson.D{{Key: "score", Value: bson.M{"$meta": "textScore"}}}
+		}
+	} else if relevance {
+		return response, lib.NewInvalidInputError(errors.New("sort by relevance requires a search"))
+	}
+	sort = append(sort, bson.E{Key: "_id", Value: 1})
+	if val, ok := args["filter"]; ok && val[0] != "" {
+		filter, err := parseFilter(val[0])
+		if err != nil {
+			return response, err
+		}
+		req["$and"] = bson.A{filter}
+	}
 	if !admin {
-		stringIds, err, _ = r.perm.ListAccessibleResourceIds(auth, PermV2InstanceTopic, permV2Client.ListOptions{}, permV2Client.Read)
+		req["$or"] = readersFilter(userId, auth)
+		if val, ok := args["shared"]; ok && val[0] == "true" {
+			req["userId"] = bson.M{"$ne": userId}
+			req["pub"] = bson.M{"$ne": true}
+		}
+	}
+
+	q := listQuery{filter: req, sort: sort}
+	if _, text := req["$text"]; !text {
+		q.collation = listCollation
+	}
+	q.projection, err = parseProjection(args, sort)
+	if err != nil {
+		return
+	}
+
+	var facetFields []string
+	if val, ok := args["facets"]; ok {
+		facetFields, err = parseFacets(val[0])
 		if err != nil {
 			return
 		}
-		for _, id := range stringIds {
-			objID, err := bson.ObjectIDFromHex(id)
-			if err != nil {
-				return lib.OperatorResponse{}, err
-			}
-			ids = append(ids, objID)
+	}
+
+	more := false
+	if val, ok := args["cursor"]; ok && val[0] != "" {
+		if skip > 0 {
+			return response, lib.NewInvalidInputError(errors.New("cursor and offset can not be combined"))
+		}
+		if relevance {
+			return response, lib.NewInvalidInputError(errors.New("cursor is not supported when sorting by relevance"))
 		}
-		req = bson.M{
-			"$or": []interface{}{
-				bson.M{"_id": bson.M{"$in": ids}},
-				bson.M{"userId": userId},
-			}}
-		if val, ok := args["search"]; ok {
-			req = bson.M{
-				"name": bson.M{"$regex": val[0]},
-				"$or": []interface{}{
-					bson.M{"_id": bson.M{"$in": ids}},
-					bson.M{"userId": userId},
-				}}
+		var cursor pageCursor
+		cursor, err = decodeCursor(sort, val[0])
+		if err != nil {
+			return
 		}
+		response, more, err = r.after(q, seekFilter(sort, cursor), limit)
+	} else if limit <= 0 {
+		response, err = r.all(q, skip)
+	} else {
+		response, more, err = r.page(q, skip, limit)
 	}
-	cur, err := r.coll.Find(context.TODO(), req, opt)
 	if err != nil {
-		util.Logger.Error("error on query", "error", err)
 		return
 	}
+	if more && !relevance {
+		response.NextCursor, err = encodeCursor(sort, response.Operators[len(response.Operators)-1])
+		if err != nil {
+			return
+		}
+	}
+	if len(facetFields) > 0 {
+		response.Facets, err = r.facets(q, facetFields)
+		if err != nil {
+			return
+		}
+	}
+	if len(terms) > 0 {
+		response.Highlights = map[string][]lib.SearchHighlight{}
+		for _, operator := range response.Operators {
+			if highlights := operator.Highlights(terms); len(highlights) > 0 {
+				response.Highlights[operator.Id.Hex()] = highlights
+			}
+		}
+	}
+	return
+}
 
-	response.Total, err = r.coll.CountDocuments(context.TODO(), req)
+// page loads one page and one additional operator to detect further pages.
+// The total is counted separately, so the count is answered from the indexes without loading every matching operator.
+func (r *MongoRepo) page(q listQuery, skip int64, limit int64) (response lib.OperatorResponse, more bool, err error) {
+	opt := options.Find().SetSkip(skip).SetLimit(limit + 1).SetSort(q.sort).SetCollation(q.collation).SetProjection(q.projection)
+	cur, err := r.coll.Find(context.TODO(), q.filter, opt)
+	if err != nil {
+		util.Logger.Error("error on query", "error", err)
+		return response, false, mongoError(err)
+	}
+	response.Operators = make([]lib.Operator, 0)
+	err = cur.All(context.TODO(), &response.Operators)
+	if err != nil {
+		return lib.OperatorResponse{}, false, mongoError(err)
+	}
+	if int64(len(response.Operators)) > limit {
+		response.Operators = response.Operators[:limit]
+		more = true
+	}
+	response.Total, err = r.coll.CountDocuments(context.TODO(), q.filter, options.Count().SetCollation(q.collation))
 	if err != nil {
 		util.Logger.Error("error on CountDocuments", "error", err)
-		return
+		return response, false, mongoError(err)
+	}
+	return
+}
+
+// after loads the operators following a cursor position, the seek filter can use the sort indexes unlike an offset.
+func (r *MongoRepo) after(q listQuery, seek bson.M, limit int64) (response lib.OperatorResponse, more bool, err error) {
+	opt := options.Find().SetSort(q.sort).SetCollation(q.collation).SetProjection(q.projection)
+	if limit > 0 {
+		opt.SetLimit(limit + 1)
+	}
+	query := maps.Clone(q.filter)
+	and, _ := query["$and"].(bson.A)
+	query["$and"] = append(slices.Clone(and), seek)
+	cur, err := r.coll.Find(context.TODO(), query, opt)
+	if err != nil {
+		util.Logger.Error("error on query", "error", err)
+		return response, false, mongoError(err)
+	}
+	response.Operators = make([]lib.Operator, 0)
+	err = cur.All(context.TODO(), &response.Operators)
+	if err != nil {
+		return lib.OperatorResponse{}, false, mongoError(err)
+	}
+	if limit > 0 && int64(len(response.Operators)) > limit {
+		response.Operators = response.Operators[:limit]
+		more = true
+	}
+	response.Total, err = r.coll.CountDocuments(context.TODO(), q.filter, options.Count().SetCollation(q.collation))
+	if err != nil {
+		util.Logger.Error("error on CountDocuments", "error", err)
+		return response, false, mongoError(err)
+	}
+	return
+}
+
+// all lists without a page size, the result could exceed the document size limit of a facet.
+func (r *MongoRepo) all(q listQuery, skip int64) (response lib.OperatorResponse, err error) {
+	opt := options.Find().SetSkip(skip).SetSort(q.sort).SetCollation(q.collation).SetProjection(q.projection)
+	cur, err := r.coll.Find(context.TODO(), q.filter, opt)
+	if err != nil {
+		util.Logger.Error("error on query", "error", err)
+		return response, mongoError(err)
 	}
 	response.Operators = make([]lib.Operator, 0)
 	err = cur.All(context.TODO(), &response.Operators)
 	if err != nil {
-		return lib.OperatorResponse{}, err
+		return lib.OperatorResponse{}, mongoError(err)
+	}
+	response.Total = skip + int64(len(response.Operators))
+	if skip > 0 && len(response.Operators) == 0 {
+		response.Total, err = r.coll.CountDocuments(context.TODO(), q.filter, options.Count().SetCollation(q.collation))
+		if err != nil {
+			util.Logger.Error("error on CountDocuments", "error", err)
+			return response, mongoError(err)
+		}
 	}
 	return
 }
 
-func (r *MongoRepo) FindOperator(id string, userId string, auth string) (operator lib.Operator, err error) {
-	objID, err := bson.ObjectIDFromHex(id)
+// FindOperator loads an operator readable by the user, the fields and view arguments select a projection.
+func (r *MongoRepo) FindOperator(id string, userId string, args map[string][]string, auth string) (operator lib.Operator, err error) {
+	projection, err := parseProjection(args, nil)
+	if err != nil {
+		return
+	}
+	objID, err := r.checkPermission(auth, id, permV2Client.Read)
 	if err != nil {
 		return
 	}
-	ok, err, _ := r.perm.CheckPermission(auth, PermV2InstanceTopic, id, permV2Client.Read)
+	err = r.coll.FindOne(context.TODO(), bson.M{"_id": objID, "dateDeleted": bson.M{"$exists": false}}, options.FindOne().SetProjection(projection)).Decode(&operator)
 	if err != nil {
-		return operator, err
+		return operator, mongoError(err)
 	}
-	if !ok {
-		return operator, errors.New(MessageMissingRights)
+	return
+}
+
+// FindOperators loads all readable operators of the list with one permission check and one query.
+// The result of every requested id is reported, operators are returned in the order of the ids.
+func (r *MongoRepo) FindOperators(ids []string, userId string, args map[string][]string, auth string) (response lib.BatchGetResponse, err error) {
+	ids = uniqueIds(ids)
+	if len(ids) > MaxBatchSize {
+		return response, lib.NewInvalidInputError(fmt.Errorf("at most %d ids are allowed", MaxBatchSize))
+	}
+	projection, err := parseProjection(args, nil)
+	if err != nil {
+		return
+	}
+	if _, exclusion := projection["readers"]; !exclusion {
+		projection = maps.Clone(projection)
+		projection["pub"] = 1
+	}
+	response.Operators = make([]lib.Operator, 0, len(ids))
+	response.Results = make([]lib.ItemStatus, 0, len(ids))
+	var validIds []string
+	var objIds []bson.ObjectID
+	for _, id := range ids {
+		objId, e := parseObjectID(id)
+		if e != nil {
+			continue
+		}
+		validIds = append(validIds, id)
+		objIds = append(objIds, objId)
+	}
+	access := map[string]bool{}
+	operators := map[string]lib.Operator{}
+	if len(validIds) > 0 {
+		var code int
+		access, err, code = r.perm.CheckMultiplePermissions(auth, PermV2InstanceTopic, validIds, permV2Client.Read)
+		if err != nil {
+			return response, permError(err, code)
+		}
+		cur, err := r.coll.Find(context.TODO(), bson.M{"_id": bson.M{"$in": objIds}, "dateDeleted": bson.M{"$exists": false}}, options.Find().SetProjection(projection))
+		if err != nil {
+			return response, mongoError(err)
+		}
+		var found []lib.Operator
+		if err = cur.All(context.TODO(), &found); err != nil {
+			return response, mongoError(err)
+		}
+		for _, operator := range found {
+			operators[operator.Id.Hex()] = operator
+		}
+	}
+	for _, id := range ids {
+		operator, found := operators[id]
+		switch {
+		case !slices.Contains(validIds, id):
+			response.Results = append(response.Results, lib.ItemStatus{Id: id, Status: http.StatusBadRequest, Detail: MessageInvalidId})
+		case !found:
+			response.Results = append(response.Results, lib.ItemStatus{Id: id, Status: http.StatusNotFound, Detail: MessageNotFound})
+		case !access[id] && !operator.Pub:
+			response.Results = append(response.Results, lib.ItemStatus{Id: id, Status: http.StatusForbidden, Detail: MessageMissingRights})
+		default:
+			response.Operators = append(response.Operators, operator)
+			response.Results = append(response.Results, lib.ItemStatus{Id: id, Status: http.StatusOK})
+		}
+	}
+	return
+}
+
+// FindWritableOperators loads the operators of an ID list or matching an RSQL filter the user may write.
+// The result of every operator is reported with an HTTP status code in the order of the ID list, writable operators with status 200.
+func (r *MongoRepo) FindWritableOperators(ids []string, filter string, userId string, auth string) (operators []lib.Operator, results []lib.ItemStatus, err error) {
+	if (len(ids) == 0) == (filter == "") {
+		return nil, nil, lib.NewInvalidInputError(errors.New("either ids or filter is required"))
+	}
+	found := map[string]lib.Operator{}
+	if filter != "" {
+		var req bson.M
+		req, err = parseFilter(filter)
+		if err != nil {
+			return
+		}
+		req = bson.M{"$and": bson.A{req, bson.M{"dateDeleted": bson.M{"$exists": false}}, bson.M{"$or": readersFilter(userId, auth)}}}
+		var cur *mongo.Cursor
+		// the filter matches strings the same way as in list queries
+		cur, err = r.coll.Find(context.TODO(), req, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).SetLimit(MaxBatchSize+1).SetProjection(defaultProjection).SetCollation(listCollation))
+		if err != nil {
+			return nil, nil, mongoError(err)
+		}
+		var matched []lib.Operator
+		if err = cur.All(context.TODO(), &matched); err != nil {
+			return nil, nil, mongoError(err)
+		}
+		if len(matched) > MaxBatchSize {
+			return nil, nil, lib.NewInvalidInputError(fmt.Errorf("filter matches more than %d operators", MaxBatchSize))
+		}
+		ids = make([]string, 0, len(matched))
+		for _, operator := range matched {
+			ids = append(ids, operator.Id.Hex())
+			found[operator.Id.Hex()] = operator
+		}
+	} else {
+		ids = uniqueIds(ids)
+		if len(ids) > MaxBatchSize {
+			return nil, nil, lib.NewInvalidInputError(fmt.Errorf("at most %d ids are allowed", MaxBatchSize))
+		}
+		var objIds []bson.ObjectID
+		for _, id := range ids {
+			if objId, e := parseObjectID(id); e == nil {
+				objIds = append(objIds, objId)
+			}
+		}
+		if len(objIds) > 0 {
+			var cur *mongo.Cursor
+			cur, err = r.coll.Find(context.TODO(), bson.M{"_id": bson.M{"$in": objIds}, "dateDeleted": bson.M{"$exists": false}}, options.Find().SetProjection(defaultProjection))
+			if err != nil {
+				return nil, nil, mongoError(err)
+			}
+			var matched []lib.Operator
+			if err = cur.All(context.TODO(), &matched); err != nil {
+				return nil, nil, mongoError(err)
+			}
+			for _, operator := range matched {
+				found[operator.Id.Hex()] = operator
+			}
+		}
+	}
+	access := map[string]bool{}
+	if len(found) > 0 {
+		var code int
+		access, err, code = r.perm.CheckMultiplePermissions(auth, PermV2InstanceTopic, slices.Collect(maps.Keys(found)), permV2Client.Write)
+		if err != nil {
+			return nil, nil, permError(err, code)
+		}
+	}
+	for _, id := range ids {
+		operator, ok := found[id]
+		switch {
+		case !ok:
+			if _, e := parseObjectID(id); e != nil {
+				results = append(results, lib.ItemStatus{Id: id, Status: http.StatusBadRequest, Detail: MessageInvalidId})
+			} else {
+				results = append(results, lib.ItemStatus{Id: id, Status: http.StatusNotFound, Detail: MessageNotFound})
+			}
+		case !access[id]:
+			results = append(results, lib.ItemStatus{Id: id, Status: http.StatusForbidden, Detail: MessageMissingRights})
+		default:
+			operators = append(operators, operator)
+			results = append(results, lib.ItemStatus{Id: id, Status: http.StatusOK})
+		}
+	}
+	return
+}
+
+func uniqueIds(ids []string) (unique []string) {
+	seen := make(map[string]struct{}, len(ids))
+	for _, id := range ids {
+		if _, ok := seen[id]; !ok {
+			seen[id] = struct{}{}
+			unique = append(unique, id)
+		}
+	}
+	return
+}
+
+// FindOperatorById loads an operator without checking permissions, it is meant for internal use only.
+func (r *MongoRepo) FindOperatorById(id string) (operator lib.Operator, err error) {
+	objID, err := parseObjectID(id)
+	if err != nil {
+		return
 	}
 	err = r.coll.FindOne(context.TODO(), bson.M{"_id": objID}).Decode(&operator)
+	return operator, mongoError(err)
+}
+
+// FindOperatorPermissionsById loads the permissions of an operator without checking the rights of a user.
+// Operators without permissions, e.g. trashed ones, have empty permissions.
+func (r *MongoRepo) FindOperatorPermissionsById(id string) (permissions lib.OperatorPermissions, err error) {
+	resource, err, code := r.perm.GetResource(permV2Client.InternalAdminToken, PermV2InstanceTopic, id)
+	if code == http.StatusNotFound {
+		return toOperatorPermissions(permV2Client.ResourcePermissions{}), nil
+	}
+	if err != nil {
+		return permissions, permError(err, code)
+	}
+	return toOperatorPermissions(resource.ResourcePermissions), nil
+}
+
+// revisionError reports a failed conditional write as precondition failure if the operator still exists outside the trash.
+func (r *MongoRepo) revisionError(objId bson.ObjectID, err error) error {
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		return mongoError(err)
+	}
+	count, e := r.coll.CountDocuments(context.TODO(), bson.M{"_id": objId, "dateDeleted": bson.M{"$exists": false}})
+	if e != nil {
+		return mongoError(e)
+	}
+	if count > 0 {
+		return lib.NewPreconditionFailedError(errors.New(MessageRevisionMismatch))
+	}
+	return mongoError(err)
+}
+
+// checkPermission verifies the users permission on an operator.
+// Public operators may be read by everyone, even if the users token lacks the public role.
+// Missing and trashed operators are reported as not found, existing operators without sufficient rights as forbidden.
+func (r *MongoRepo) checkPermission(auth string, id string, permission permV2Client.Permission) (objId bson.ObjectID, err error) {
+	objId, err = parseObjectID(id)
 	if err != nil {
 		return
 	}
+	var operator lib.Operator
+	err = r.coll.FindOne(context.TODO(), bson.M{"_id": objId, "dateDeleted": bson.M{"$exists": false}}, options.FindOne().SetProjection(bson.M{"pub": 1})).Decode(&operator)
+	if err != nil {
+		return objId, mongoError(err)
+	}
+	ok, err, code := r.perm.CheckPermission(auth, PermV2InstanceTopic, id, permission)
+	if err != nil {
+		return objId, permError(err, code)
+	}
+	if !ok {
+		if operator.Pub && (permission == permV2Client.Read || permission == permV2Client.Execute) {
+			return objId, nil
+		}
+		return objId, lib.NewForbiddenError(errors.New(MessageMissingRights))
+	}
 	return
 }
@@ -0,0 +1,147 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package db
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SENERGY-Platform/analytics-operator-repo-v2/lib"
+	"github.com/SENERGY-Platform/analytics-operator-repo-v2/pkg/util"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// The benchmarks list operators of a collection with benchOperators operators.
+// They need a mongo database given by BENCH_MONGO_URL, e.g. localhost:27017, and are skipped otherwise.
+const (
+	benchOperators = 100_000
+	benchUsers     = 100
+)
+
+type benchOperator struct {
+	lib.Operator `bson:",inline"`
+	Readers      readers `bson:"readers"`
+}
+
+func benchRepo(b *testing.B) *MongoRepo {
+	url := os.Getenv("BENCH_MONGO_URL")
+	if url == "" {
+		b.Skip("BENCH_MONGO_URL not set")
+	}
+	util.InitStructLogger("error")
+	database, err := New(url)
+	if err != nil {
+		b.Fatal(err)
+	}
+	mdb := database.client.Database("operator_repo_bench")
+	repo := &MongoRepo{coll: mdb.Collection("operators"), versionColl: mdb.Collection("operator_versions")}
+	b.Cleanup(func() {
+		if err := mdb.Drop(context.Background()); err != nil {
+			b.Error(err)
+		}
+		database.Disconnect(context.Background())
+	})
+	if err = repo.CreateIndexes(); err != nil {
+		b.Fatal(err)
+	}
+	now := time.Now()
+	batch := make([]benchOperator, 0, 1000)
+	for i := range benchOperators {
+		userId := fmt.Sprintf("user%d", i%benchUsers)
+		batch = append(batch, benchOperator{
+			Operator: lib.Operator{
+				Name:        fmt.Sprintf("operator %06d", (i*7919)%benchOperators),
+				Description: "benchmark operator",
+				Tags:        []string{fmt.Sprintf("tag%d", i%10)},
+				UserId:      userId,
+				Pub:         i%10 == 0,
+				Version:     "1.0.0",
+				Published:   true,
+				DateCreated: now,
+				DateUpdated: now,
+				Revision:    1,
+			},
+			Readers: readers{Users: []string{userId}, Groups: []string{}, Roles: []string{}},
+		})
+		if len(batch) == cap(batch) {
+			if _, err = repo.coll.InsertMany(context.Background(), batch); err != nil {
+				b.Fatal(err)
+			}
+			batch = batch[:0]
+		}
+	}
+	return repo
+}
+
+func BenchmarkAll(b *testing.B) {
+	repo := benchRepo(b)
+	cases := []struct {
+		name  string
+		admin bool
+		args  map[string][]string
+	}{
+		{name: "admin first page", admin: true, args: map[string][]string{"limit": {"20"}}},
+		{name: "admin deep page", admin: true, args: map[string][]string{"limit": {"20"}, "offset": {"50000"}, "sort": {"name:asc"}}},
+		{name: "admin filter", admin: true, args: map[string][]string{"limit": {"20"}, "filter": {"tags==tag3"}, "sort": {"name:asc"}}},
+		{name: "user first page", args: map[string][]string{"limit": {"20"}, "sort": {"name:asc"}}},
+		{name: "user summary", args: map[string][]string{"limit": {"20"}, "sort": {"name:asc"}, "view": {ViewSummary}}},
+	}
+	for _, c := range cases {
+		b.Run(c.name, func(b *testing.B) {
+			for b.Loop() {
+				response, err := repo.All("user1", c.admin, c.args, "")
+				if err != nil {
+					b.Fatal(err)
+				}
+				if response.Total == 0 {
+					b.Fatal("no operators found")
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkUserCount counts the operators readable by a user, the query plan must not scan the collection.
+func BenchmarkUserCount(b *testing.B) {
+	repo := benchRepo(b)
+	filter := bson.M{"dateDeleted": bson.M{"$exists": false}, "$or": readersFilter("user1", "")}
+	var explain bson.M
+	err := repo.coll.Database().RunCommand(context.Background(), bson.D{
+		{Key: "explain", Value: bson.D{
+			{Key: "count", Value: repo.coll.Name()},
+			{Key: "query", Value: filter},
+			{Key: "collation", Value: bson.M{"locale": listCollation.Locale, "strength": listCollation.Strength}},
+		}},
+		{Key: "verbosity", Value: "queryPlanner"},
+	}).Decode(&explain)
+	if err != nil {
+		b.Fatal(err)
+	}
+	if plan := fmt.Sprint(explain["queryPlanner"]); strings.Contains(plan, "COLLSCAN") {
+		b.Fatalf("readers filter scans the collection: %s", plan)
+	}
+	for b.Loop() {
+		if _, err = repo.coll.CountDocuments(context.Background(), filter, options.Count().SetCollation(listCollation)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
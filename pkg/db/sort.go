@@ -0,0 +1,82 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package db
+
+import (
+	"errors"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/SENERGY-Platform/analytics-operator-repo-v2/lib"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const SortRelevance = "relevance"
+
+// sortFields maps the sortable fields to their database path.
+var sortFields = map[string]string{
+	"name":           "name",
+	"dateCreated":    "dateCreated",
+	"dateUpdated":    "dateUpdated",
+	"cost":           "cost",
+	"deploymentType": "deploymentType",
+	"owner":          "userId",
+	"userId":         "userId",
+	"pub":            "pub",
+	"published":      "published",
+}
+
+// listCollation compares strings case-insensitively, so names sort like users expect.
+// Text search does not support collations, searches are sorted by binary comparison.
+var listCollation = &options.Collation{Locale: "en", Strength: 2}
+
+// parseSort reads a comma separated list of field:direction pairs, e.g. pub:desc,name:asc.
+// The direction defaults to asc, relevance sorts by the text search score.
+func parseSort(value string) (sort bson.D, relevance bool, err error) {
+	for _, part := range strings.Split(value, ",") {
+		field, direction, _ := strings.Cut(strings.TrimSpace(part), ":")
+		order := 1
+		switch direction {
+		case "", "asc":
+		case "desc":
+			order = -1
+		default:
+			return nil, false, lib.NewInvalidInputError(errors.New("invalid sort direction " + strconv.Quote(direction) + ", expected asc or desc"))
+		}
+		if field == SortRelevance {
+			if relevance {
+				return nil, false, lib.NewInvalidInputError(errors.New("duplicate sort field " + field))
+			}
+			relevance = true
+			sort = append(sort, bson.E{Key: "score", Value: bson.M{"$meta": "textScore"}})
+			continue
+		}
+		path, ok := sortFields[field]
+		if !ok {
+			names := append(slices.Sorted(maps.Keys(sortFields)), SortRelevance)
+			return nil, false, lib.NewInvalidInputError(errors.New("invalid sort field " + strconv.Quote(field) + ", allowed are " + strings.Join(names, ", ")))
+		}
+		if slices.ContainsFunc(sort, func(e bson.E) bool { return e.Key == path }) {
+			return nil, false, lib.NewInvalidInputError(errors.New("duplicate sort field " + field))
+		}
+		sort = append(sort, bson.E{Key: path, Value: order})
+	}
+	return
+}
@@ -0,0 +1,190 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package db
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/SENERGY-Platform/analytics-operator-repo-v2/lib"
+	"github.com/SENERGY-Platform/analytics-operator-repo-v2/pkg/util"
+	permV2Client "github.com/SENERGY-Platform/permissions-v2/pkg/client"
+	permV2Model "github.com/SENERGY-Platform/permissions-v2/pkg/model"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// Deleted operators are kept in the trash until they are purged.
+// Their permissions are removed from permissions-v2 and stored with the operator, so a restore can bring them back.
+const trashPermissionsField = "trashPermissions"
+
+type trashedOperator struct {
+	lib.Operator `bson:",inline"`
+	Permissions  *permV2Client.ResourcePermissions `bson:"trashPermissions,omitempty"`
+}
+
+func trashIndexes() []mongo.IndexModel {
+	return []mongo.IndexModel{
+		{Keys: bson.D{{Key: "dateDeleted", Value: 1}}, Options: options.Index().SetSparse(true)},
+	}
+}
+
+// trashOperator moves an operator to the trash and removes its permissions, everything changed is recorded in d.
+func (r *MongoRepo) trashOperator(d *deletion, userId string, revision *int64) (err error) {
+	objId, err := parseObjectID(d.id)
+	if err != nil {
+		return
+	}
+	resource, err, code := r.perm.GetResource(permV2Client.InternalAdminToken, PermV2InstanceTopic, d.id)
+	if err != nil && code != http.StatusNotFound {
+		return permError(err, code)
+	}
+	set := bson.M{"dateDeleted": time.Now(), "deletedBy": userId}
+	if err == nil {
+		d.permissions = &resource.ResourcePermissions
+		set[trashPermissionsField] = resource.ResourcePermissions
+	}
+	req := bson.M{"_id": objId, "dateDeleted": bson.M{"$exists": false}}
+	if revision != nil {
+		req["revision"] = *revision
+	}
+	d.operator, err = r.coll.FindOneAndUpdate(context.TODO(), req, bson.M{
+		"$set":   set,
+		"$unset": bson.M{"readers": ""},
+		"$inc":   bson.M{"revision": 1},
+	}).Raw()
+	if err != nil {
+		return r.revisionError(objId, err)
+	}
+	if d.permissions != nil {
+		err, code = r.perm.RemoveResource(permV2Client.InternalAdminToken, PermV2InstanceTopic, d.id)
+		if err != nil {
+			return permError(err, code)
+		}
+		d.removedPerm = true
+	}
+	return
+}
+
+// FindTrashedOperators lists deleted operators, latest deletion first.
+// Users see the operators they own or deleted themselves, admins see all.
+func (r *MongoRepo) FindTrashedOperators(userId string, admin bool, args map[string][]string) (response lib.OperatorResponse, err error) {
+	req := bson.M{"dateDeleted": bson.M{"$exists": true}}
+	if !admin {
+		req["$or"] = bson.A{bson.M{"userId": userId}, bson.M{"deletedBy": userId}}
+	}
+	opt := options.Find().SetSort(bson.D{{Key: "dateDeleted", Value: -1}, {Key: "_id", Value: 1}}).SetProjection(defaultProjection)
+	if val, ok := args["limit"]; ok {
+		limit, _ := strconv.ParseInt(val[0], 10, 64)
+		opt.SetLimit(limit)
+	}
+	if val, ok := args["offset"]; ok {
+		skip, _ := strconv.ParseInt(val[0], 10, 64)
+		opt.SetSkip(skip)
+	}
+	cur, err := r.coll.Find(context.TODO(), req, opt)
+	if err != nil {
+		return response, mongoError(err)
+	}
+	response.Operators = make([]lib.Operator, 0)
+	if err = cur.All(context.TODO(), &response.Operators); err != nil {
+		return response, mongoError(err)
+	}
+	response.Total, err = r.coll.CountDocuments(context.TODO(), req)
+	return response, mongoError(err)
+}
+
+// RestoreOperator takes an operator out of the trash and restores the permissions it had when it was deleted.
+// The owner, the user who deleted it and admins may restore an operator.
+func (r *MongoRepo) RestoreOperator(id string, userId string, admin bool) (operator lib.Operator, err error) {
+	objId, err := parseObjectID(id)
+	if err != nil {
+		return
+	}
+	var trashed trashedOperator
+	err = r.coll.FindOne(context.TODO(), bson.M{"_id": objId, "dateDeleted": bson.M{"$exists": true}}).Decode(&trashed)
+	if err != nil {
+		return operator, mongoError(err)
+	}
+	if !admin && trashed.UserId != userId && trashed.DeletedBy != userId {
+		return operator, lib.NewForbiddenError(errors.New(MessageMissingRights))
+	}
+	permissions := permV2Client.ResourcePermissions{
+		UserPermissions:  map[string]permV2Client.PermissionsMap{},
+		GroupPermissions: map[string]permV2Client.PermissionsMap{},
+		RolePermissions:  map[string]permV2Model.PermissionsMap{},
+	}
+	if trashed.Permissions != nil {
+		permissions = fromOperatorPermissions(toOperatorPermissions(*trashed.Permissions))
+	}
+	operator = trashed.Operator
+	operator.DateDeleted = nil
+	operator.DeletedBy = ""
+	SetDefaultPermissions(operator, permissions)
+	res, err := r.coll.UpdateOne(context.TODO(), bson.M{"_id": objId, "revision": trashed.Revision}, bson.M{
+		"$unset": bson.M{"dateDeleted": "", "deletedBy": "", trashPermissionsField: ""},
+		"$inc":   bson.M{"revision": 1},
+	})
+	if err != nil {
+		return operator, mongoError(err)
+	}
+	if res.MatchedCount == 0 {
+		return operator, lib.NewPreconditionFailedError(errors.New(MessageRevisionMismatch))
+	}
+	operator.Revision++
+	_, err = r.setPermission(id, permissions)
+	if err != nil {
+		if _, e := r.coll.UpdateByID(context.TODO(), objId, bson.M{"$set": bson.M{"dateDeleted": trashed.DateDeleted, "deletedBy": trashed.DeletedBy, trashPermissionsField: trashed.Permissions}}); e != nil {
+			util.Logger.Error("error on moving operator back to trash", "error", e, "id", id)
+		}
+	}
+	return
+}
+
+// PurgeTrash finally deletes operators that have been in the trash since before the given time, including their versions.
+// beforePurge is called with each operator first, operators it fails for are kept until the next purge.
+// The purged operators are returned as stored before.
+func (r *MongoRepo) PurgeTrash(before time.Time, beforePurge func(operator lib.Operator) error) (purged []lib.Operator, err error) {
+	cur, err := r.coll.Find(context.TODO(), bson.M{"dateDeleted": bson.M{"$lt": before}})
+	if err != nil {
+		return nil, mongoError(err)
+	}
+	var operators []lib.Operator
+	if err = cur.All(context.TODO(), &operators); err != nil {
+		return nil, mongoError(err)
+	}
+	for _, operator := range operators {
+		id := operator.Id.Hex()
+		if e := beforePurge(operator); e != nil {
+			continue
+		}
+		_, err = r.versionColl.DeleteMany(context.TODO(), bson.M{"operatorId": id})
+		if err != nil {
+			return purged, mongoError(err)
+		}
+		_, err = r.coll.DeleteOne(context.TODO(), bson.M{"_id": operator.Id, "dateDeleted": bson.M{"$lt": before}})
+		if err != nil {
+			return purged, mongoError(err)
+		}
+		purged = append(purged, operator)
+	}
+	return
+}
@@ -0,0 +1,60 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package events
+
+import (
+	"context"
+	"time"
+
+	"github.com/SENERGY-Platform/analytics-operator-repo-v2/pkg/util"
+)
+
+const (
+	retryDelay    = 100 * time.Millisecond
+	maxRetryDelay = 30 * time.Second
+)
+
+type Handler func(msg []byte) error
+
+// Subscriber delivers the messages of a topic to a handler until the context is done.
+type Subscriber interface {
+	Subscribe(ctx context.Context, topic string, handler Handler) error
+}
+
+// Publisher sends a message to a topic, messages with the same key keep their order.
+type Publisher interface {
+	Publish(ctx context.Context, topic string, key string, msg []byte) error
+}
+
+// handle passes the message to the handler until it succeeds or the context is done.
+// Failed attempts are retried with exponential backoff, so a message is never skipped.
+func handle(ctx context.Context, topic string, handler Handler, msg []byte) error {
+	delay := retryDelay
+	for {
+		err := handler(msg)
+		if err == nil {
+			return nil
+		}
+		util.Logger.Error("error handling message, retrying", "error", err, "topic", topic, "retryIn", delay.String())
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, maxRetryDelay)
+	}
+}
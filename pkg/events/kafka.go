@@ -0,0 +1,92 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package events
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+type KafkaSubscriber struct {
+	brokers []string
+	groupId string
+}
+
+func NewKafkaSubscriber(bootstrap string, groupId string) *KafkaSubscriber {
+	return &KafkaSubscriber{brokers: strings.Split(bootstrap, ","), groupId: groupId}
+}
+
+// Subscribe commits every message after it has been handled, failed messages are retried until they succeed.
+// Messages still failing when the context is done are not committed.
+func (s *KafkaSubscriber) Subscribe(ctx context.Context, topic string, handler Handler) error {
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers: s.brokers,
+		GroupID: s.groupId,
+		Topic:   topic,
+	})
+	defer reader.Close()
+	for {
+		msg, err := reader.FetchMessage(ctx)
+		if err != nil {
+			if errors.Is(err, context.Canceled) {
+				return nil
+			}
+			return err
+		}
+		if err = handle(ctx, topic, handler, msg.Value); err != nil {
+			// the message is not committed and will be delivered again
+			return nil
+		}
+		if err = reader.CommitMessages(ctx, msg); err != nil {
+			if errors.Is(err, context.Canceled) {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+type KafkaPublisher struct {
+	writer *kafka.Writer
+}
+
+func NewKafkaPublisher(bootstrap string) *KafkaPublisher {
+	return &KafkaPublisher{writer: &kafka.Writer{
+		Addr:                   kafka.TCP(strings.Split(bootstrap, ",")...),
+		Balancer:               &kafka.Hash{},
+		RequiredAcks:           kafka.RequireAll,
+		AllowAutoTopicCreation: true,
+		// messages are written one at a time, waiting for the default of one second to fill a batch would delay each of them
+		BatchTimeout: 10 * time.Millisecond,
+	}}
+}
+
+func (p *KafkaPublisher) Publish(ctx context.Context, topic string, key string, msg []byte) error {
+	return p.writer.WriteMessages(ctx, kafka.Message{
+		Topic: topic,
+		Key:   []byte(key),
+		Value: msg,
+	})
+}
+
+func (p *KafkaPublisher) Close() error {
+	return p.writer.Close()
+}
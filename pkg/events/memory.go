@@ -0,0 +1,78 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package events
+
+import (
+	"context"
+	"sync"
+
+	"github.com/SENERGY-Platform/analytics-operator-repo-v2/pkg/util"
+)
+
+// MemoryBroker is an in-process stand-in for kafka.
+// Messages published before subscribing or to a subscriber that falls behind are dropped.
+type MemoryBroker struct {
+	mux  sync.RWMutex
+	subs map[string][]chan []byte
+}
+
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{subs: map[string][]chan []byte{}}
+}
+
+func (b *MemoryBroker) Publish(_ context.Context, topic string, _ string, msg []byte) error {
+	b.mux.RLock()
+	defer b.mux.RUnlock()
+	for _, ch := range b.subs[topic] {
+		select {
+		case ch <- msg:
+		default:
+			util.Logger.Warn("dropping message of slow subscriber", "topic", topic)
+		}
+	}
+	return nil
+}
+
+func (b *MemoryBroker) Subscribe(ctx context.Context, topic string, handler Handler) error {
+	ch := make(chan []byte, 64)
+	b.mux.Lock()
+	b.subs[topic] = append(b.subs[topic], ch)
+	b.mux.Unlock()
+	defer b.unsubscribe(topic, ch)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg := <-ch:
+			if err := handle(ctx, topic, handler, msg); err != nil {
+				return nil
+			}
+		}
+	}
+}
+
+func (b *MemoryBroker) unsubscribe(topic string, ch chan []byte) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	subs := b.subs[topic]
+	for i, sub := range subs {
+		if sub == ch {
+			b.subs[topic] = append(subs[:i], subs[i+1:]...)
+			return
+		}
+	}
+}
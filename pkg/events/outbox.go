@@ -0,0 +1,210 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/SENERGY-Platform/analytics-operator-repo-v2/lib"
+	"github.com/SENERGY-Platform/analytics-operator-repo-v2/pkg/util"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const outboxBatchSize = 100
+
+// eventRevisionField holds the latest operator revision an event has been stored for.
+const eventRevisionField = "eventRevision"
+
+type outboxEntry struct {
+	Id          bson.ObjectID `bson:"_id,omitempty"`
+	Key         string        `bson:"key"`
+	Message     []byte        `bson:"message"`
+	DateCreated time.Time     `bson:"dateCreated"`
+}
+
+// Outbox stores operator events in mongo and relays them to the publisher in insertion order.
+// Entries are only removed after they have been published, which results in at-least-once delivery.
+//
+// Events are stored after the change itself, so they can get lost in between. Every change of an operator increments
+// its revision, the outbox marks the revision of each stored event on the operator. Operators with a newer revision
+// than their mark on two consecutive recoveries get an event derived from their current state.
+type Outbox struct {
+	coll             *mongo.Collection
+	operators        *mongo.Collection
+	publisher        Publisher
+	topic            string
+	interval         time.Duration
+	recoveryInterval time.Duration
+	notify           chan struct{}
+	pending          map[bson.ObjectID]int64
+}
+
+func NewOutbox(coll *mongo.Collection, operators *mongo.Collection, publisher Publisher, topic string, interval time.Duration, recoveryInterval time.Duration) *Outbox {
+	return &Outbox{
+		coll:             coll,
+		operators:        operators,
+		publisher:        publisher,
+		topic:            topic,
+		interval:         interval,
+		recoveryInterval: recoveryInterval,
+		notify:           make(chan struct{}, 1),
+		pending:          map[bson.ObjectID]int64{},
+	}
+}
+
+// HandleOperatorEvent stores the event as serialized JSON, as arbitrary diff values would not survive a bson round trip.
+// Afterward the revision of the event operator is marked as stored.
+func (o *Outbox) HandleOperatorEvent(event lib.OperatorEvent) error {
+	msg, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = o.coll.InsertOne(context.TODO(), outboxEntry{
+		Key:         event.Data.Id,
+		Message:     msg,
+		DateCreated: time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+	select {
+	case o.notify <- struct{}{}:
+	default:
+	}
+	if operator := event.Data.Operator; operator != nil && operator.Id != nil {
+		// a failed mark results in a duplicate event on recovery only
+		if _, err = o.operators.UpdateByID(context.TODO(), *operator.Id, bson.M{"$max": bson.M{eventRevisionField: operator.Revision}}); err != nil {
+			util.Logger.Warn("error marking operator event as stored", "error", err, "id", event.Data.Id)
+		}
+	}
+	return nil
+}
+
+// Init marks the current revisions of operators without mark as stored, so existing operators are not recovered.
+// Operators created within the recovery interval are left out, their events may not have been stored yet.
+func (o *Outbox) Init() error {
+	_, err := o.operators.UpdateMany(context.TODO(), bson.M{
+		eventRevisionField: bson.M{"$exists": false},
+		"_id":              bson.M{"$lt": bson.NewObjectIDFromTimestamp(time.Now().Add(-o.recoveryInterval))},
+	}, mongo.Pipeline{{{Key: "$set", Value: bson.M{eventRevisionField: "$revision"}}}})
+	return err
+}
+
+// Run relays pending events on every insert and retries failed deliveries periodically until the context is done.
+// Lost events are recovered every recovery interval.
+func (o *Outbox) Run(ctx context.Context) {
+	ticker := time.NewTicker(o.interval)
+	defer ticker.Stop()
+	recovery := time.NewTicker(o.recoveryInterval)
+	defer recovery.Stop()
+	for {
+		if err := o.relay(ctx); err != nil && ctx.Err() == nil {
+			util.Logger.Warn("error relaying operator events", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-o.notify:
+		case <-recovery.C:
+			if err := o.recover(ctx); err != nil && ctx.Err() == nil {
+				util.Logger.Warn("error recovering operator events", "error", err)
+			}
+		}
+	}
+}
+
+// recover stores events for operators whose revision has been unmarked since the last recovery.
+// The operators are compared field by field, which scans the collection once per recovery interval.
+func (o *Outbox) recover(ctx context.Context) error {
+	cur, err := o.operators.Find(ctx, bson.M{
+		"$expr": bson.M{"$gt": bson.A{"$revision", bson.M{"$ifNull": bson.A{"$" + eventRevisionField, 0}}}},
+	}, options.Find().SetProjection(bson.M{"_id": 1, "revision": 1}))
+	if err != nil {
+		return err
+	}
+	var unmarked []lib.Operator
+	if err = cur.All(ctx, &unmarked); err != nil {
+		return err
+	}
+	pending := map[bson.ObjectID]int64{}
+	var errs []error
+	for _, operator := range unmarked {
+		if revision, ok := o.pending[*operator.Id]; !ok || revision != operator.Revision {
+			pending[*operator.Id] = operator.Revision
+			continue
+		}
+		if err = o.recoverOperator(ctx, *operator.Id); err != nil {
+			pending[*operator.Id] = operator.Revision
+			errs = append(errs, err)
+		}
+	}
+	o.pending = pending
+	return errors.Join(errs...)
+}
+
+// recoverOperator stores an event with the current state of the operator, without diff, user and request ID.
+func (o *Outbox) recoverOperator(ctx context.Context, id bson.ObjectID) error {
+	var operator lib.Operator
+	err := o.operators.FindOne(ctx, bson.M{"_id": id}).Decode(&operator)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	eventType := lib.EventTypeOperatorUpdated
+	if operator.DateDeleted != nil {
+		eventType = lib.EventTypeOperatorTrashed
+	} else if operator.Revision == 1 {
+		eventType = lib.EventTypeOperatorCreated
+	}
+	operator.RedactSecrets()
+	event := lib.NewOperatorEvent(eventType, id.Hex(), "")
+	event.Data.Operator = &operator
+	util.Logger.Info("recovering lost operator event", "id", event.Data.Id, "type", eventType, "revision", operator.Revision)
+	return o.HandleOperatorEvent(event)
+}
+
+func (o *Outbox) relay(ctx context.Context) error {
+	for {
+		cur, err := o.coll.Find(ctx, bson.M{}, options.Find().SetSort(bson.M{"_id": 1}).SetLimit(outboxBatchSize))
+		if err != nil {
+			return err
+		}
+		var entries []outboxEntry
+		if err = cur.All(ctx, &entries); err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if err = o.publisher.Publish(ctx, o.topic, entry.Key, entry.Message); err != nil {
+				return err
+			}
+			if _, err = o.coll.DeleteOne(ctx, bson.M{"_id": entry.Id}); err != nil {
+				return err
+			}
+		}
+		if len(entries) < outboxBatchSize {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,52 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package events
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/SENERGY-Platform/analytics-operator-repo-v2/pkg/util"
+)
+
+const UserCommandDelete = "DELETE"
+
+type UserCommand struct {
+	Command string `json:"command"`
+	Id      string `json:"id"`
+}
+
+type UserDeletionHandler interface {
+	HandleUserDeleted(userId string) error
+}
+
+// ConsumeUserEvents passes the ids of deleted users to the handler until the context is done.
+func ConsumeUserEvents(ctx context.Context, sub Subscriber, topic string, handler UserDeletionHandler) error {
+	return sub.Subscribe(ctx, topic, func(msg []byte) error {
+		var cmd UserCommand
+		if err := json.Unmarshal(msg, &cmd); err != nil {
+			// retrying can not fix a malformed message
+			util.Logger.Error("skipping invalid user command", "error", err)
+			return nil
+		}
+		if cmd.Command != UserCommandDelete || cmd.Id == "" {
+			return nil
+		}
+		util.Logger.Info("handling deleted user", "userId", cmd.Id)
+		return handler.HandleUserDeleted(cmd.Id)
+	})
+}
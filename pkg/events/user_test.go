@@ -0,0 +1,120 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package events
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SENERGY-Platform/analytics-operator-repo-v2/pkg/util"
+)
+
+const testTopic = "user"
+
+func TestMain(m *testing.M) {
+	util.InitStructLogger("error")
+	m.Run()
+}
+
+type userHandlerMock struct {
+	mux      sync.Mutex
+	failures int
+	calls    []string
+}
+
+func (m *userHandlerMock) HandleUserDeleted(userId string) error {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	m.calls = append(m.calls, userId)
+	if m.failures > 0 {
+		m.failures--
+		return errors.New("temporary failure")
+	}
+	return nil
+}
+
+func (m *userHandlerMock) getCalls() []string {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	return slices.Clone(m.calls)
+}
+
+func startConsumer(t *testing.T, broker *MemoryBroker, handler UserDeletionHandler) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- ConsumeUserEvents(ctx, broker, testTopic, handler)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Error(err)
+		}
+	})
+	waitFor(t, func() bool {
+		broker.mux.RLock()
+		defer broker.mux.RUnlock()
+		return len(broker.subs[testTopic]) > 0
+	})
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timeout")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func publish(t *testing.T, broker *MemoryBroker, msg string) {
+	if err := broker.Publish(context.Background(), testTopic, "", []byte(msg)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestConsumeUserEvents(t *testing.T) {
+	broker := NewMemoryBroker()
+	handler := &userHandlerMock{}
+	startConsumer(t, broker, handler)
+	publish(t, broker, `not json`)
+	publish(t, broker, `{"command":"PUT","id":"user1"}`)
+	publish(t, broker, `{"command":"DELETE","id":""}`)
+	publish(t, broker, `{"command":"DELETE","id":"user2"}`)
+	waitFor(t, func() bool { return len(handler.getCalls()) > 0 })
+	if calls := handler.getCalls(); !slices.Equal(calls, []string{"user2"}) {
+		t.Errorf("expected only user2 to be handled, got %v", calls)
+	}
+}
+
+func TestConsumeUserEventsRetry(t *testing.T) {
+	broker := NewMemoryBroker()
+	handler := &userHandlerMock{failures: 2}
+	startConsumer(t, broker, handler)
+	publish(t, broker, `{"command":"DELETE","id":"user1"}`)
+	publish(t, broker, `{"command":"DELETE","id":"user2"}`)
+	waitFor(t, func() bool { return len(handler.getCalls()) >= 4 })
+	if calls := handler.getCalls(); !slices.Equal(calls, []string{"user1", "user1", "user1", "user2"}) {
+		t.Errorf("expected failed message to be retried before the next one, got %v", calls)
+	}
+}
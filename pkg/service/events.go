@@ -0,0 +1,146 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+
+	"github.com/SENERGY-Platform/analytics-operator-repo-v2/lib"
+	"github.com/SENERGY-Platform/analytics-operator-repo-v2/pkg/util"
+)
+
+// EventHandler receives every operator lifecycle event after the change has been stored.
+type EventHandler interface {
+	HandleOperatorEvent(event lib.OperatorEvent) error
+}
+
+type eventHandlers struct {
+	mux      sync.RWMutex
+	handlers []EventHandler
+}
+
+func (s *Service) AddEventHandler(handler EventHandler) {
+	s.eventHandlers.mux.Lock()
+	defer s.eventHandlers.mux.Unlock()
+	s.eventHandlers.handlers = append(s.eventHandlers.handlers, handler)
+}
+
+// emit passes the event to all handlers, secret config defaults are never part of an event.
+// The change has already been stored at this point, errors of the handlers are logged and returned but do not fail the request.
+func (s *Service) emit(event lib.OperatorEvent) (err error) {
+	event.Data.RequestId = s.requestId
+	if event.Data.Operator != nil {
+		operator := *event.Data.Operator
+		operator.RedactSecrets()
+		event.Data.Operator = &operator
+	}
+	s.eventHandlers.mux.RLock()
+	defer s.eventHandlers.mux.RUnlock()
+	var errs []error
+	for _, handler := range s.eventHandlers.handlers {
+		if err = handler.HandleOperatorEvent(event); err != nil {
+			util.Logger.Error("error handling operator event", "error", err, "type", event.Type, "id", event.Data.Id)
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *Service) emitCreated(operator lib.Operator, userId string) {
+	event := lib.NewOperatorEvent(lib.EventTypeOperatorCreated, operator.Id.Hex(), userId)
+	event.Data.Operator = &operator
+	s.emit(event)
+}
+
+// emitUpdated loads the stored operator and emits it together with the changes to before.
+func (s *Service) emitUpdated(id string, userId string, before lib.Operator) {
+	after, err := s.dbRepo.FindOperatorById(id)
+	if err != nil {
+		util.Logger.Error("error loading updated operator", "error", err, "id", id)
+		return
+	}
+	event := lib.NewOperatorEvent(lib.EventTypeOperatorUpdated, id, userId)
+	event.Data.Operator = &after
+	before.RedactSecrets()
+	redacted := after
+	redacted.RedactSecrets()
+	event.Data.Diff = lib.DiffOperators(before, redacted)
+	s.emit(event)
+}
+
+// emitTrashed emits the operator as stored in the trash, or as before if it cannot be loaded.
+func (s *Service) emitTrashed(before lib.Operator, userId string) {
+	operator, err := s.dbRepo.FindOperatorById(before.Id.Hex())
+	if err != nil {
+		util.Logger.Error("error loading trashed operator", "error", err, "id", before.Id.Hex())
+		operator = before
+	}
+	event := lib.NewOperatorEvent(lib.EventTypeOperatorTrashed, operator.Id.Hex(), userId)
+	event.Data.Operator = &operator
+	s.emit(event)
+}
+
+func (s *Service) emitRestored(operator lib.Operator, userId string) {
+	event := lib.NewOperatorEvent(lib.EventTypeOperatorRestored, operator.Id.Hex(), userId)
+	event.Data.Operator = &operator
+	s.emit(event)
+}
+
+// emitPurged is called before the operator is purged, which is skipped if a handler fails.
+func (s *Service) emitPurged(operator lib.Operator) error {
+	event := lib.NewOperatorEvent(lib.EventTypeOperatorPurged, operator.Id.Hex(), "")
+	event.Data.Operator = &operator
+	return s.emit(event)
+}
+
+func (s *Service) emitPermissionsChanged(id string, userId string, previous *lib.OperatorPermissions, permissions *lib.OperatorPermissions) {
+	event := lib.NewOperatorEvent(lib.EventTypeOperatorPermissionsChanged, id, userId)
+	event.Data.PreviousPermissions = previous
+	event.Data.Permissions = permissions
+	s.emit(event)
+}
+
+// permissionsOf loads the current permissions of the operators, so changes made afterward can be emitted.
+func (s *Service) permissionsOf(ids ...string) map[string]lib.OperatorPermissions {
+	permissions := make(map[string]lib.OperatorPermissions, len(ids))
+	for _, id := range ids {
+		p, err := s.dbRepo.FindOperatorPermissionsById(id)
+		if err != nil {
+			util.Logger.Error("error loading operator permissions", "error", err, "id", id)
+			continue
+		}
+		permissions[id] = p
+	}
+	return permissions
+}
+
+// emitPermissionChanges emits a permissions changed event for every operator whose permissions differ from before.
+// Operators missing in before are reported without previous permissions.
+func (s *Service) emitPermissionChanges(userId string, before map[string]lib.OperatorPermissions, ids ...string) {
+	for id, after := range s.permissionsOf(ids...) {
+		previous, ok := before[id]
+		if !ok {
+			s.emitPermissionsChanged(id, userId, nil, &after)
+			continue
+		}
+		if !reflect.DeepEqual(previous, after) {
+			s.emitPermissionsChanged(id, userId, &previous, &after)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"errors"
+
+	"github.com/SENERGY-Platform/analytics-operator-repo-v2/lib"
+	"github.com/SENERGY-Platform/analytics-operator-repo-v2/pkg/db"
+)
+
+// historyRecorder stores every operator event as history entry.
+type historyRecorder struct {
+	store *db.HistoryStore
+}
+
+func (h *historyRecorder) HandleOperatorEvent(event lib.OperatorEvent) error {
+	return h.store.Insert(lib.NewHistoryEntry(event))
+}
+
+// WithRequestId returns a copy of the service that records the request ID with every change it makes.
+func (s *Service) WithRequestId(requestId string) *Service {
+	c := *s
+	c.requestId = requestId
+	return &c
+}
+
+// GetOperatorHistory lists the changes of an operator the user may read, latest first.
+// Like the permissions themselves, changed permissions are only shown to users with administrate rights.
+func (s *Service) GetOperatorHistory(id string, userId string, args map[string][]string, auth string) (response lib.HistoryResponse, err error) {
+	_, err = s.dbRepo.FindOperator(id, userId, map[string][]string{"fields": {"_id"}}, auth)
+	if err != nil {
+		return
+	}
+	response, err = s.history.Find(id, args)
+	if err != nil {
+		return
+	}
+	if _, e := s.dbRepo.FindOperatorPermissions(id, userId, auth); e != nil {
+		var forbidden *lib.ForbiddenError
+		if !errors.As(e, &forbidden) {
+			return response, e
+		}
+		for i := range response.Entries {
+			response.Entries[i].Permissions = nil
+			response.Entries[i].PreviousPermissions = nil
+		}
+	}
+	return
+}
+
+// GetAuditHistory lists the changes of all operators, it is meant for admins only.
+func (s *Service) GetAuditHistory(args map[string][]string) (response lib.HistoryResponse, err error) {
+	return s.history.Find("", args)
+}
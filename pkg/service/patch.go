@@ -0,0 +1,219 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/SENERGY-Platform/analytics-operator-repo-v2/lib"
+	"github.com/SENERGY-Platform/analytics-operator-repo-v2/pkg/db"
+	"github.com/SENERGY-Platform/analytics-operator-repo-v2/pkg/util"
+	jsonpatch "github.com/evanphx/json-patch/v5"
+)
+
+const messageSecretDefault = "only the owner may access defaults of secret config values"
+
+// PatchOperator applies a JSON merge patch (RFC 7396) or a JSON patch (RFC 6902) to the operator and returns the result.
+// The patch is applied to the revision read here, changes in the meantime fail the update.
+func (s *Service) PatchOperator(id string, patchType string, patch []byte, userId string, revision *int64, auth string) (result lib.Operator, err error) {
+	revision, err = s.checkRevision(revision)
+	if err != nil {
+		return
+	}
+	current, err := s.dbRepo.FindOperator(id, userId, nil, auth)
+	if err != nil {
+		return
+	}
+	if revision != nil && *revision != current.Revision {
+		return result, lib.NewPreconditionFailedError(errors.New(db.MessageRevisionMismatch))
+	}
+	// others patch the operator as they can read it, the stored secret defaults are kept on update
+	owner := current.UserId == userId
+	if !owner {
+		current.RedactSecrets()
+	}
+	operator, err := applyPatch(current, patchType, patch, !owner)
+	if err != nil {
+		return
+	}
+	err = s.UpdateOperator(id, operator, userId, &current.Revision, auth)
+	if err != nil {
+		return
+	}
+	return s.GetOperator(id, userId, nil, auth)
+}
+
+// applyPatch patches the JSON representation of the operator and decodes it strictly.
+// Fields maintained by the service can not be changed by a patch. With protectSecrets, the patch may neither read nor set
+// the defaults of secret config values.
+func applyPatch(current lib.Operator, patchType string, patch []byte, protectSecrets bool) (operator lib.Operator, err error) {
+	doc, err := json.Marshal(current)
+	if err != nil {
+		return
+	}
+	switch patchType {
+	case lib.PatchTypeMerge:
+		doc, err = jsonpatch.MergePatch(doc, patch)
+	case lib.PatchTypeJSON:
+		var ops jsonpatch.Patch
+		ops, err = jsonpatch.DecodePatch(patch)
+		if err != nil {
+			return operator, lib.NewInvalidInputError(fmt.Errorf("invalid patch: %w", err))
+		}
+		if protectSecrets {
+			if err = checkSecretPaths(current, ops); err != nil {
+				return
+			}
+		}
+		doc, err = ops.Apply(doc)
+	default:
+		return operator, lib.NewUnsupportedMediaTypeError(fmt.Errorf("unsupported patch type %q, expected %s or %s", patchType, lib.PatchTypeMerge, lib.PatchTypeJSON))
+	}
+	if err != nil {
+		return operator, lib.NewInvalidInputError(fmt.Errorf("invalid patch: %w", err))
+	}
+	decoder := json.NewDecoder(bytes.NewReader(doc))
+	decoder.DisallowUnknownFields()
+	if err = decoder.Decode(&operator); err != nil {
+		return operator, lib.NewInvalidInputError(fmt.Errorf("patched operator is invalid: %w", err))
+	}
+	if operator.Name == "" {
+		return operator, lib.NewInvalidInputError(errors.New("patched operator is invalid: name is required"))
+	}
+	if protectSecrets {
+		for _, value := range operator.Config {
+			if value.Secret && value.Default != nil {
+				return operator, lib.NewForbiddenError(errors.New(messageSecretDefault))
+			}
+		}
+	}
+	operator.Id = current.Id
+	operator.UserId = current.UserId
+	operator.Published = current.Published
+	operator.Revision = current.Revision
+	operator.DateCreated = current.DateCreated
+	operator.DateUpdated = current.DateUpdated
+	operator.DateDeleted = current.DateDeleted
+	operator.DeletedBy = current.DeletedBy
+	return
+}
+
+// BulkUpdateOperators applies a JSON merge patch to every operator of the request the user may write.
+// Each operator is updated on its own, so a failing operator does not stop the others.
+func (s *Service) BulkUpdateOperators(request lib.BulkUpdateRequest, userId string, auth string) (response lib.BulkUpdateResponse, err error) {
+	operators, results, err := s.dbRepo.FindWritableOperators(request.Ids, request.Filter, userId, auth)
+	if err != nil {
+		return
+	}
+	writable := make(map[string]lib.Operator, len(operators))
+	for _, operator := range operators {
+		writable[operator.Id.Hex()] = operator
+	}
+	for i, result := range results {
+		if result.Status == http.StatusOK {
+			results[i] = s.bulkUpdateOperator(writable[result.Id], request.Patch, userId, auth)
+		}
+		switch status := results[i].Status; {
+		case status == http.StatusOK:
+			response.Updated++
+		case status == http.StatusNotModified:
+			response.Skipped++
+		case status == http.StatusForbidden:
+			response.Forbidden++
+		default:
+			response.Failed++
+		}
+	}
+	response.Results = results
+	if response.Results == nil {
+		response.Results = []lib.ItemStatus{}
+	}
+	return
+}
+
+func (s *Service) bulkUpdateOperator(current lib.Operator, patch []byte, userId string, auth string) lib.ItemStatus {
+	id := current.Id.Hex()
+	owner := current.UserId == userId
+	if !owner {
+		current.RedactSecrets()
+	}
+	operator, err := applyPatch(current, lib.PatchTypeMerge, patch, !owner)
+	if err == nil && unchanged(current, operator) {
+		return lib.ItemStatus{Id: id, Status: http.StatusNotModified, Detail: "unchanged"}
+	}
+	if err == nil {
+		err = s.UpdateOperator(id, operator, userId, &current.Revision, auth)
+	}
+	if err != nil {
+		status := lib.StatusCode(err)
+		if status == 0 {
+			util.Logger.Error("error updating operator", "error", err, "id", id)
+			return lib.ItemStatus{Id: id, Status: http.StatusInternalServerError, Detail: "something went wrong"}
+		}
+		return lib.ItemStatus{Id: id, Status: status, Detail: err.Error()}
+	}
+	return lib.ItemStatus{Id: id, Status: http.StatusOK}
+}
+
+// unchanged compares operators by their JSON representation, numbers decoded from JSON and BSON differ in type only.
+func unchanged(a lib.Operator, b lib.Operator) bool {
+	aj, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	bj, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+	return jsonpatch.Equal(aj, bj)
+}
+
+// checkSecretPaths rejects patch operations that read or write the default of a secret config value.
+func checkSecretPaths(operator lib.Operator, ops jsonpatch.Patch) error {
+	for _, op := range ops {
+		for _, get := range []func() (string, error){op.Path, op.From} {
+			path, err := get()
+			if err != nil {
+				continue
+			}
+			parts := strings.Split(path, "/")
+			if len(parts) < 3 || parts[1] != "config_values" {
+				continue
+			}
+			if parts[2] == "-" {
+				if len(parts) > 3 {
+					return lib.NewForbiddenError(errors.New(messageSecretDefault))
+				}
+				continue
+			}
+			i, err := strconv.Atoi(parts[2])
+			if err != nil || i < 0 || i >= len(operator.Config) || !operator.Config[i].Secret {
+				continue
+			}
+			if len(parts) == 3 || parts[3] == "default" {
+				return lib.NewForbiddenError(errors.New(messageSecretDefault))
+			}
+		}
+	}
+	return nil
+}
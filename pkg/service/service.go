@@ -17,47 +17,457 @@
 package service
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
 	"github.com/SENERGY-Platform/analytics-operator-repo-v2/lib"
+	"github.com/SENERGY-Platform/analytics-operator-repo-v2/pkg/config"
 	"github.com/SENERGY-Platform/analytics-operator-repo-v2/pkg/db"
+	"github.com/SENERGY-Platform/analytics-operator-repo-v2/pkg/util"
 	srv_info_hdl "github.com/SENERGY-Platform/go-service-base/srv-info-hdl"
 	permV2Client "github.com/SENERGY-Platform/permissions-v2/pkg/client"
 )
 
+const MaxIdempotencyKeyLength = 255
+
 type Service struct {
-	srvInfoHdl srv_info_hdl.Handler
-	dbRepo     db.OperatorRepository
+	srvInfoHdl    srv_info_hdl.Handler
+	dbRepo        db.OperatorRepository
+	userEvents    config.UserEventsConfig
+	eventHandlers *eventHandlers
+	stream        *eventStream
+	idempotency   *db.IdempotencyStore
+	requireRev    bool
+	history       *db.HistoryStore
+	requestId     string
 }
 
-func New(srvInfoHdl srv_info_hdl.Handler, perm permV2Client.Client, database db.MongoDB) (*Service, error) {
-	dbRepo := db.NewMongoRepo(perm, database.OperatorCollection())
-	err := dbRepo.ValidateOperatorPermissions()
-	return &Service{
-		srvInfoHdl: srvInfoHdl,
-		dbRepo:     dbRepo,
-	}, err
+func New(srvInfoHdl srv_info_hdl.Handler, perm permV2Client.Client, database db.MongoDB, cfg *config.Config) (*Service, error) {
+	for _, policy := range []string{cfg.UserEvents.PublicPolicy, cfg.UserEvents.SharedPolicy} {
+		if !slices.Contains([]string{config.UserDeletePolicyDelete, config.UserDeletePolicyKeep, config.UserDeletePolicyReassign}, policy) {
+			return nil, errors.New("invalid user deletion policy: " + policy)
+		}
+		if policy == config.UserDeletePolicyReassign && cfg.UserEvents.ReassignTo == "" {
+			return nil, errors.New("user deletion policy " + policy + " requires reassign_to")
+		}
+	}
+	dbRepo := db.NewMongoRepo(perm, database.OperatorCollection(), database.OperatorVersionCollection())
+	err := dbRepo.CreateIndexes()
+	if err != nil {
+		return nil, err
+	}
+	err = dbRepo.MigrateOperatorVersions()
+	if err != nil {
+		return nil, err
+	}
+	err = dbRepo.MigrateOperatorRevisions()
+	if err != nil {
+		return nil, err
+	}
+	idempotency := db.NewIdempotencyStore(database.IdempotencyCollection(), cfg.IdempotencyTTL)
+	err = idempotency.CreateIndexes()
+	if err != nil {
+		return nil, err
+	}
+	history := db.NewHistoryStore(database.HistoryCollection())
+	err = history.CreateIndexes()
+	if err != nil {
+		return nil, err
+	}
+	err = dbRepo.ValidateOperatorPermissions()
+	s := &Service{
+		srvInfoHdl:    srvInfoHdl,
+		dbRepo:        dbRepo,
+		userEvents:    cfg.UserEvents,
+		eventHandlers: &eventHandlers{},
+		stream:        newEventStream(),
+		idempotency:   idempotency,
+		requireRev:    cfg.RequireIfMatch,
+		history:       history,
+	}
+	s.AddEventHandler(&historyRecorder{store: history})
+	s.AddEventHandler(s.stream)
+	return s, err
 }
 
-func (s *Service) CreateOperator(operator lib.Operator, userId string) (err error) {
+// CreateOperator stores a new operator of the user and returns it.
+// With an idempotency key, repeating the request returns the operator created first instead of a duplicate.
+// The operator is loaded like any other read, it may have been transferred or trashed in the meantime.
+func (s *Service) CreateOperator(operator lib.Operator, userId string, idempotencyKey string, auth string) (result lib.Operator, err error) {
 	operator.UserId = userId
-	return s.dbRepo.InsertOperator(operator)
+	if idempotencyKey == "" {
+		return s.createOperator(operator, userId)
+	}
+	if len(idempotencyKey) > MaxIdempotencyKeyLength {
+		return result, lib.NewInvalidInputError(fmt.Errorf("idempotency key must not be longer than %d characters", MaxIdempotencyKeyLength))
+	}
+	requestHash, err := hashRequest(operator)
+	if err != nil {
+		return
+	}
+	record, reserved, err := s.idempotency.Reserve(userId, idempotencyKey, requestHash)
+	if err != nil {
+		return
+	}
+	if !reserved {
+		return s.GetOperator(record.OperatorId, userId, nil, auth)
+	}
+	result, err = s.createOperator(operator, userId)
+	if err != nil {
+		if e := s.idempotency.Release(userId, idempotencyKey); e != nil {
+			util.Logger.Error("error releasing idempotency key", "error", e)
+		}
+		return
+	}
+	if e := s.idempotency.Complete(userId, idempotencyKey, result.Id.Hex()); e != nil {
+		// the operator exists, failing the request would make the client retry and create a duplicate once the lease expired
+		util.Logger.Error("error completing idempotency key", "error", e, "id", result.Id.Hex())
+	}
+	return
 }
 
-func (s *Service) UpdateOperator(id string, operator lib.Operator, userId string, auth string) (err error) {
-	return s.dbRepo.UpdateOperator(id, operator, userId, auth)
+func (s *Service) createOperator(operator lib.Operator, userId string) (result lib.Operator, err error) {
+	result, err = s.dbRepo.InsertOperator(operator)
+	if err != nil {
+		return
+	}
+	s.emitCreated(result, userId)
+	s.emitPermissionChanges(userId, nil, result.Id.Hex())
+	return
 }
 
-func (s *Service) DeleteOperator(id string, userId string, auth string) (err error) {
-	return s.dbRepo.DeleteOperator(id, userId, false, auth)
+// UpdateOperator updates the operator if revision is nil or matches the stored revision.
+func (s *Service) UpdateOperator(id string, operator lib.Operator, userId string, revision *int64, auth string) (err error) {
+	revision, err = s.checkRevision(revision)
+	if err != nil {
+		return
+	}
+	before, _ := s.dbRepo.FindOperatorById(id)
+	var permissions map[string]lib.OperatorPermissions
+	if before.Pub != operator.Pub {
+		// the public role is granted or revoked
+		permissions = s.permissionsOf(id)
+	}
+	err = s.dbRepo.UpdateOperator(id, operator, userId, revision, auth)
+	if err != nil {
+		return
+	}
+	s.emitUpdated(id, userId, before)
+	if permissions != nil {
+		s.emitPermissionChanges(userId, permissions, id)
+	}
+	return
 }
 
-func (s *Service) DeleteOperators(ids []string, userId string, auth string) (err error) {
-	return s.dbRepo.DeleteOperators(ids, userId, false, auth)
+// DeleteOperator deletes the operator if revision is nil or matches the stored revision.
+func (s *Service) DeleteOperator(id string, userId string, revision *int64, auth string) (err error) {
+	revision, err = s.checkRevision(revision)
+	if err != nil {
+		return
+	}
+	before, _ := s.dbRepo.FindOperatorById(id)
+	err = s.dbRepo.DeleteOperator(id, userId, revision, false, auth)
+	if err != nil {
+		return
+	}
+	s.emitTrashed(before, userId)
+	return
+}
+
+// DeleteOperators deletes all operators of the ID list or none of them, on a dry run the operators are checked only.
+func (s *Service) DeleteOperators(ids []string, userId string, dryRun bool, auth string) (response lib.BatchDeleteResponse, err error) {
+	befores := map[string]lib.Operator{}
+	if !dryRun {
+		for _, id := range ids {
+			if before, e := s.dbRepo.FindOperatorById(id); e == nil {
+				befores[id] = before
+			}
+		}
+	}
+	response, err = s.dbRepo.DeleteOperators(ids, userId, false, dryRun, auth)
+	if err != nil || dryRun {
+		return
+	}
+	for _, id := range response.Deleted {
+		if before, ok := befores[id]; ok {
+			s.emitTrashed(before, userId)
+		}
+	}
+	return
 }
 
 func (s *Service) GetOperators(userId string, args map[string][]string, auth string) (response lib.OperatorResponse, err error) {
-	return s.dbRepo.All(userId, false, args, auth)
+	response, err = s.dbRepo.All(userId, false, args, auth)
+	if err != nil {
+		return
+	}
+	for i := range response.Operators {
+		redactSecrets(&response.Operators[i], userId)
+	}
+	return
+}
+
+func (s *Service) GetOperator(id string, userId string, args map[string][]string, auth string) (response lib.Operator, err error) {
+	response, err = s.dbRepo.FindOperator(id, userId, args, auth)
+	if err != nil {
+		return
+	}
+	redactSecrets(&response, userId)
+	return
+}
+
+func (s *Service) BatchGetOperators(ids []string, userId string, args map[string][]string, auth string) (response lib.BatchGetResponse, err error) {
+	response, err = s.dbRepo.FindOperators(ids, userId, args, auth)
+	if err != nil {
+		return
+	}
+	for i := range response.Operators {
+		redactSecrets(&response.Operators[i], userId)
+	}
+	return
+}
+
+// PublishOperator publishes the current version if revision is nil or matches the stored revision.
+func (s *Service) PublishOperator(id string, userId string, revision *int64, auth string) (err error) {
+	revision, err = s.checkRevision(revision)
+	if err != nil {
+		return
+	}
+	before, _ := s.dbRepo.FindOperatorById(id)
+	err = s.dbRepo.PublishOperator(id, userId, revision, auth)
+	if err != nil {
+		return
+	}
+	s.emitUpdated(id, userId, before)
+	return
+}
+
+func (s *Service) GetOperatorVersions(id string, userId string, auth string) (response lib.OperatorVersionsResponse, err error) {
+	response, err = s.dbRepo.FindOperatorVersions(id, userId, auth)
+	if err != nil {
+		return
+	}
+	for i := range response.Versions {
+		redactSecrets(&response.Versions[i].Operator, userId)
+	}
+	return
+}
+
+func (s *Service) GetOperatorVersion(id string, version string, userId string, auth string) (response lib.OperatorVersion, err error) {
+	response, err = s.dbRepo.FindOperatorVersion(id, version, userId, auth)
+	if err != nil {
+		return
+	}
+	redactSecrets(&response.Operator, userId)
+	return
+}
+
+func (s *Service) ValidateOperatorConfig(id string, config map[string]any, userId string, auth string) (response lib.ConfigValidationResponse, err error) {
+	operator, err := s.dbRepo.FindOperator(id, userId, nil, auth)
+	if err != nil {
+		return
+	}
+	return lib.ValidateConfig(operator.Config, config), nil
+}
+
+func (s *Service) GetOperatorPermissions(id string, userId string, auth string) (permissions lib.OperatorPermissions, err error) {
+	return s.dbRepo.FindOperatorPermissions(id, userId, auth)
+}
+
+func (s *Service) SetOperatorPermissions(id string, permissions lib.OperatorPermissions, userId string, auth string) (result lib.OperatorPermissions, err error) {
+	previous, err := s.dbRepo.FindOperatorPermissions(id, userId, auth)
+	if err != nil {
+		return
+	}
+	result, err = s.dbRepo.SetOperatorPermissions(id, permissions, userId, auth)
+	if err != nil {
+		return
+	}
+	s.emitPermissionsChanged(id, userId, &previous, &result)
+	return
+}
+
+func (s *Service) TransferOperator(id string, newUserId string, userId string, auth string) (err error) {
+	before, _ := s.dbRepo.FindOperatorById(id)
+	permissions := s.permissionsOf(id)
+	err = s.dbRepo.TransferOperator(id, newUserId, userId, auth)
+	if err != nil {
+		return
+	}
+	s.emitUpdated(id, userId, before)
+	s.emitPermissionChanges(userId, permissions, id)
+	return
+}
+
+func (s *Service) ReassignOperators(fromUserId string, toUserId string, userId string) (response lib.ReassignResponse, err error) {
+	befores, err := s.dbRepo.FindUserOperators(fromUserId)
+	if err != nil {
+		return
+	}
+	ids := make([]string, 0, len(befores))
+	for _, before := range befores {
+		ids = append(ids, before.Id.Hex())
+	}
+	permissions := s.permissionsOf(ids...)
+	response, err = s.dbRepo.ReassignOperators(fromUserId, toUserId)
+	if err != nil {
+		return
+	}
+	for _, before := range befores {
+		if slices.Contains(response.Transferred, before.Id.Hex()) {
+			s.emitUpdated(before.Id.Hex(), userId, before)
+		}
+	}
+	s.emitPermissionChanges(userId, permissions, response.Transferred...)
+	return
+}
+
+// HandleUserDeleted deletes the private operators of a deleted user and removes the users grants.
+// Public and shared operators are deleted, kept or reassigned according to the configured policies.
+func (s *Service) HandleUserDeleted(userId string) (err error) {
+	operators, err := s.dbRepo.FindUserOperators(userId)
+	if err != nil {
+		return
+	}
+	var errs []error
+	var kept []string
+	for _, operator := range operators {
+		id := operator.Id.Hex()
+		policy := config.UserDeletePolicyDelete
+		if operator.Pub {
+			policy = s.userEvents.PublicPolicy
+		} else {
+			shared, err := s.dbRepo.IsOperatorShared(operator)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			if shared {
+				policy = s.userEvents.SharedPolicy
+			}
+		}
+		switch policy {
+		case config.UserDeletePolicyDelete:
+			if err = s.dbRepo.DeleteOperator(id, userId, nil, true, ""); err == nil {
+				s.emitTrashed(operator, "")
+			}
+		case config.UserDeletePolicyReassign:
+			permissions := s.permissionsOf(id)
+			if err = s.dbRepo.AdminTransferOperator(id, s.userEvents.ReassignTo); err == nil {
+				s.emitUpdated(id, "", operator)
+				s.emitPermissionChanges("", permissions, id)
+			}
+		default:
+			util.Logger.Info("keeping operator of deleted user", "id", id, "userId", userId)
+			kept = append(kept, id)
+			err = nil
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	changes, err := s.dbRepo.RemoveUserPermissions(userId, kept)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	for _, change := range changes {
+		s.emitPermissionsChanged(change.Id, "", &change.Previous, &change.Current)
+	}
+	return errors.Join(errs...)
+}
+
+func (s *Service) GetTrashedOperators(userId string, admin bool, args map[string][]string) (response lib.OperatorResponse, err error) {
+	response, err = s.dbRepo.FindTrashedOperators(userId, admin, args)
+	if err != nil {
+		return
+	}
+	for i := range response.Operators {
+		redactSecrets(&response.Operators[i], userId)
+	}
+	return
+}
+
+// RestoreOperator takes an operator out of the trash and restores its permissions.
+func (s *Service) RestoreOperator(id string, userId string, admin bool) (operator lib.Operator, err error) {
+	operator, err = s.dbRepo.RestoreOperator(id, userId, admin)
+	if err != nil {
+		return
+	}
+	s.emitRestored(operator, userId)
+	s.emitPermissionChanges(userId, nil, id)
+	redactSecrets(&operator, userId)
+	return
+}
+
+// RunTrashPurger periodically deletes operators that have been in the trash longer than the retention period until ctx is done.
+func (s *Service) RunTrashPurger(ctx context.Context, interval time.Duration, retention time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			operators, err := s.dbRepo.PurgeTrash(time.Now().Add(-retention), s.emitPurged)
+			if err != nil {
+				util.Logger.Error("error purging operator trash", "error", err)
+			}
+			if len(operators) > 0 {
+				util.Logger.Info("purged operators from trash", "count", len(operators))
+			}
+		}
+	}
+}
+
+// RunReadersSync periodically rebuilds the permission projection used for listing until ctx is done.
+func (s *Service) RunReadersSync(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.dbRepo.SyncReaders(); err != nil {
+				util.Logger.Error("error syncing operator readers", "error", err)
+			}
+		}
+	}
+}
+
+// checkRevision enforces conditional requests if they are required by the configuration.
+// The returned revision is nil if any revision matches.
+func (s *Service) checkRevision(revision *int64) (*int64, error) {
+	if revision == nil {
+		if s.requireRev {
+			return nil, lib.NewPreconditionRequiredError(errors.New("missing If-Match header"))
+		}
+		return nil, nil
+	}
+	if *revision == lib.AnyRevision {
+		return nil, nil
+	}
+	return revision, nil
+}
+
+func hashRequest(request any) (string, error) {
+	b, err := json.Marshal(request)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
 }
 
-func (s *Service) GetOperator(id string, userId string, auth string) (response lib.Operator, err error) {
-	return s.dbRepo.FindOperator(id, userId, auth)
+// redactSecrets hides secret config defaults from everyone but the owner.
+func redactSecrets(operator *lib.Operator, userId string) {
+	if operator.UserId != userId {
+		operator.RedactSecrets()
+	}
 }
@@ -0,0 +1,177 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/SENERGY-Platform/analytics-operator-repo-v2/lib"
+	"github.com/SENERGY-Platform/analytics-operator-repo-v2/pkg/util"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const (
+	streamHistorySize = 1000
+	streamBufferSize  = 100
+)
+
+// eventStream fans operator events out to stream subscribers and keeps the latest events for resumption.
+// Only events of this instance are seen, the kafka topic is the source of truth across replicas.
+type eventStream struct {
+	mux         sync.Mutex
+	history     []lib.OperatorEvent
+	subscribers map[chan lib.OperatorEvent]struct{}
+}
+
+func newEventStream() *eventStream {
+	return &eventStream{
+		subscribers: make(map[chan lib.OperatorEvent]struct{}),
+	}
+}
+
+func (e *eventStream) HandleOperatorEvent(event lib.OperatorEvent) error {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+	e.history = append(e.history, event)
+	if len(e.history) > streamHistorySize {
+		e.history = e.history[len(e.history)-streamHistorySize:]
+	}
+	for ch := range e.subscribers {
+		select {
+		case ch <- event:
+		default:
+			util.Logger.Warn("dropping operator event for slow stream subscriber", "id", event.Id)
+		}
+	}
+	return nil
+}
+
+// subscribe registers a new subscriber and returns the events after lastEventId.
+// If lastEventId is no longer in the history, all kept events created at or after its timestamp are returned.
+func (e *eventStream) subscribe(lastEventId string) (ch chan lib.OperatorEvent, replay []lib.OperatorEvent, err error) {
+	var lastTimestamp bson.ObjectID
+	if lastEventId != "" {
+		lastTimestamp, err = bson.ObjectIDFromHex(lastEventId)
+		if err != nil {
+			return nil, nil, lib.NewInvalidInputError(errors.New("invalid last event id: " + lastEventId))
+		}
+	}
+	e.mux.Lock()
+	defer e.mux.Unlock()
+	if lastEventId != "" {
+		found := false
+		for i, event := range e.history {
+			if event.Id == lastEventId {
+				replay = append(replay, e.history[i+1:]...)
+				found = true
+				break
+			}
+		}
+		if !found {
+			for _, event := range e.history {
+				if id, err := bson.ObjectIDFromHex(event.Id); err == nil && !id.Timestamp().Before(lastTimestamp.Timestamp()) {
+					replay = append(replay, event)
+				}
+			}
+		}
+	}
+	ch = make(chan lib.OperatorEvent, streamBufferSize)
+	e.subscribers[ch] = struct{}{}
+	return ch, replay, nil
+}
+
+func (e *eventStream) unsubscribe(ch chan lib.OperatorEvent) {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+	delete(e.subscribers, ch)
+}
+
+// StreamOperatorEvents returns created, updated and deleted events of all operators the user can read until ctx is done.
+// Events after lastEventId are replayed first.
+func (s *Service) StreamOperatorEvents(ctx context.Context, lastEventId string, userId string, auth string) (<-chan lib.OperatorEvent, error) {
+	ch, replay, err := s.stream.subscribe(lastEventId)
+	if err != nil {
+		return nil, err
+	}
+	out := make(chan lib.OperatorEvent)
+	go func() {
+		defer close(out)
+		defer s.stream.unsubscribe(ch)
+		// readable caches permission checks, deleted operators can not be checked anymore
+		readable := make(map[string]bool)
+		forward := func(event lib.OperatorEvent) bool {
+			if !s.canReadEvent(event, readable, userId, auth) {
+				return true
+			}
+			select {
+			case out <- event:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+		for _, event := range replay {
+			if !forward(event) {
+				return
+			}
+		}
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event := <-ch:
+				if !forward(event) {
+					return
+				}
+			}
+		}
+	}()
+	return out, nil
+}
+
+func (s *Service) canReadEvent(event lib.OperatorEvent, readable map[string]bool, userId string, auth string) bool {
+	id := event.Data.Id
+	switch event.Type {
+	case lib.EventTypeOperatorPermissionsChanged:
+		delete(readable, id)
+		return false
+	case lib.EventTypeOperatorTrashed, lib.EventTypeOperatorPurged:
+		defer delete(readable, id)
+		if event.Data.Operator != nil && (event.Data.Operator.UserId == userId || event.Data.Operator.Pub) {
+			return true
+		}
+		return readable[id]
+	case lib.EventTypeOperatorRestored:
+		delete(readable, id)
+	case lib.EventTypeOperatorUpdated:
+		// ownership and the pub flag decide who may read the operator
+		for _, change := range event.Data.Diff {
+			if change.Field == "pub" || change.Field == "userId" {
+				delete(readable, id)
+				break
+			}
+		}
+	}
+	if ok, cached := readable[id]; cached {
+		return ok
+	}
+	_, err := s.dbRepo.FindOperator(id, userId, nil, auth)
+	readable[id] = err == nil
+	return err == nil
+}